@@ -0,0 +1,350 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// blinkPeriod is the half period of the "blink" style, emulated because gocui has no blinking attribute
+const blinkPeriod = 500 * time.Millisecond
+
+// AlertRule is a condition on a value of the items, with the style applied to the items that match it.
+// Op is one of "<", "<=", ">", ">=", "==", "!=" and "~" (regular expression match). The values are compared as
+// numbers when Value is numeric, and as strings otherwise. An item lacking the key, or with a value that is not a
+// number when compared to a numeric Value, doesn't match.
+// Style is one of "red", "green", "yellow", "blue", "magenta", "cyan", "bold", "underline", "reverse" and "blink".
+type AlertRule struct {
+	Key   string
+	Op    string
+	Value string
+	Style string
+}
+
+// AlertingMonitorable is an optional interface of a Monitorable that declares alert rules.
+// The rules are evaluated on each item at each fetch, the first matching rule styles the row of the item in the
+// list, and each matching rule styles the cell of its key in the table.
+type AlertingMonitorable interface {
+	Monitorable
+
+	// AlertRules returns the rules, the first ones prevailing
+	AlertRules() []AlertRule
+}
+
+// AlertListener is an optional interface of a Monitorable that is notified when an item enters or leaves the
+// alert state of a rule. OnAlert is called from the GUI loop and should return quickly.
+type AlertListener interface {
+	OnAlert(item MonitoredItem, rule AlertRule, active bool)
+}
+
+var alertStyles = map[string]string{
+	"red":       ansiRed,
+	"green":     ansiGreen,
+	"yellow":    ansiYellow,
+	"blue":      ansiBlue,
+	"magenta":   ansiMagenta,
+	"cyan":      ansiCyan,
+	"bold":      ansiBold,
+	"underline": ansiUnder,
+	"reverse":   ansiReverse,
+	"blink":     ansiReverse,
+}
+
+var alertOps = []string{"<=", ">=", "==", "!=", "<", ">", "~"}
+
+// ParseAlertRule parses a rule like "temperature > 70 -> red". The key extends up to the first operator, the value
+// is everything after the operator and before the last arrow.
+func ParseAlertRule(s string) (AlertRule, error) {
+	var rule AlertRule
+	arrow, width := strings.LastIndex(s, "->"), len("->")
+	if alt := strings.LastIndex(s, "→"); alt > arrow {
+		arrow, width = alt, len("→")
+	}
+	if arrow < 0 {
+		return rule, fmt.Errorf("rule %q: no style", s)
+	}
+	condition := s[:arrow]
+	rule.Style = strings.TrimSpace(s[arrow+width:])
+
+	start := strings.IndexAny(condition, "<>=!~")
+	if start < 0 {
+		return rule, fmt.Errorf("rule %q: no operator", s)
+	}
+	// The operators are listed the longest first
+	for _, op := range alertOps {
+		if strings.HasPrefix(condition[start:], op) {
+			rule.Key, rule.Op = strings.TrimSpace(condition[:start]), op
+			rule.Value = strings.TrimSpace(condition[start+len(op):])
+			break
+		}
+	}
+	if rule.Op == "" {
+		return rule, fmt.Errorf("rule %q: unknown operator at %q", s, condition[start:])
+	}
+	_, err := compileAlertRule(rule)
+	return rule, err
+}
+
+// alertRule is an AlertRule ready to be evaluated
+type alertRule struct {
+	AlertRule
+	style     string
+	re        *regexp.Regexp
+	threshold float64
+	numeric   bool
+}
+
+// alertState tells which rules an item matches
+type alertState struct {
+	item  MonitoredItem
+	rules []int
+}
+
+func compileAlertRule(rule AlertRule) (alertRule, error) {
+	r := alertRule{AlertRule: rule}
+	if rule.Key == "" {
+		return r, fmt.Errorf("rule on %q: no key", rule.Key)
+	}
+	style, ok := alertStyles[rule.Style]
+	if !ok {
+		return r, fmt.Errorf("rule on %q: unknown style %q", rule.Key, rule.Style)
+	}
+	r.style = style
+
+	switch rule.Op {
+	case "~":
+		re, err := regexp.Compile(rule.Value)
+		if err != nil {
+			return r, fmt.Errorf("rule on %q: %w", rule.Key, err)
+		}
+		r.re = re
+	case "<", "<=", ">", ">=", "==", "!=":
+		threshold, err := strconv.ParseFloat(rule.Value, 64)
+		r.threshold, r.numeric = threshold, err == nil
+	default:
+		return r, fmt.Errorf("rule on %q: unknown operator %q", rule.Key, rule.Op)
+	}
+	return r, nil
+}
+
+func (r *alertRule) matches(item MonitoredItem) bool {
+	found := false
+	for _, k := range item.GetKeys() {
+		if k == r.Key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	value := strings.TrimSpace(item.GetValue(r.Key))
+	if r.re != nil {
+		return r.re.MatchString(value)
+	}
+
+	cmp := 0
+	if r.numeric {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case v < r.threshold:
+			cmp = -1
+		case v > r.threshold:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(value, r.Value)
+	}
+
+	switch r.Op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "==":
+		return cmp == 0
+	default:
+		return cmp != 0
+	}
+}
+
+// setAlertRules compiles the rules, the invalid ones are reported and ignored
+func (app *monitorApp) setAlertRules(rules []AlertRule) error {
+	app.rules = make([]alertRule, 0, len(rules))
+	var errs []error
+	for _, rule := range rules {
+		r, err := compileAlertRule(rule)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			app.rules = append(app.rules, r)
+		}
+	}
+	return joinErrors("invalid alert rules", errs)
+}
+
+// matchingRules lists the indices of the rules matched by the item
+func (app *monitorApp) matchingRules(item MonitoredItem) []int {
+	var out []int
+	if _, ok := item.(*removedItem); ok {
+		return out
+	}
+	for i := range app.rules {
+		if app.rules[i].matches(item) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// evaluateAlerts evaluates the rules on all the items, and notifies the changes of alert states
+func (app *monitorApp) evaluateAlerts() {
+	if len(app.rules) == 0 {
+		return
+	}
+	former := app.alerts
+	app.alerts = make(map[string]alertState)
+	for _, item := range app.items {
+		if rules := app.matchingRules(item); len(rules) > 0 {
+			app.alerts[primaryValue(item)] = alertState{item: item, rules: rules}
+		}
+	}
+	for pk, state := range app.alerts {
+		app.notifyAlerts(state.item, former[pk].rules, state.rules)
+	}
+	for pk, state := range former {
+		if _, ok := app.alerts[pk]; !ok {
+			app.notifyAlerts(state.item, state.rules, nil)
+		}
+	}
+	app.startBlinking()
+}
+
+// evaluateItemAlerts evaluates the rules on a single item that changed, or that was deleted when item is nil
+func (app *monitorApp) evaluateItemAlerts(pk string, item MonitoredItem) {
+	if len(app.rules) == 0 {
+		return
+	}
+	if app.alerts == nil {
+		app.alerts = make(map[string]alertState)
+	}
+	former := app.alerts[pk]
+	delete(app.alerts, pk)
+	if item == nil {
+		app.notifyAlerts(former.item, former.rules, nil)
+		return
+	}
+	rules := app.matchingRules(item)
+	if len(rules) > 0 {
+		app.alerts[pk] = alertState{item: item, rules: rules}
+	}
+	app.notifyAlerts(item, former.rules, rules)
+	app.startBlinking()
+}
+
+func (app *monitorApp) notifyAlerts(item MonitoredItem, former, current []int) {
+	listener, ok := app.source.(AlertListener)
+	if !ok {
+		return
+	}
+	for _, i := range current {
+		if !containsInt(former, i) {
+			listener.OnAlert(item, app.rules[i].AlertRule, true)
+		}
+	}
+	for _, i := range former {
+		if !containsInt(current, i) {
+			listener.OnAlert(item, app.rules[i].AlertRule, false)
+		}
+	}
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// alertStyle returns the style of the first rule matched by the item, or an empty string
+func (app *monitorApp) alertStyle(item MonitoredItem) string {
+	state, ok := app.alerts[primaryValue(item)]
+	if !ok || state.item != item {
+		return ""
+	}
+	return app.ruleStyle(state.rules[0])
+}
+
+// alertCellStyle returns the style of the first rule on the given key matched by the item, or an empty string
+func (app *monitorApp) alertCellStyle(item MonitoredItem, k string) string {
+	state, ok := app.alerts[primaryValue(item)]
+	if !ok || state.item != item {
+		return ""
+	}
+	for _, i := range state.rules {
+		if app.rules[i].Key == k {
+			return app.ruleStyle(i)
+		}
+	}
+	return ""
+}
+
+func (app *monitorApp) ruleStyle(i int) string {
+	if app.rules[i].Style == "blink" && !app.blinkOn {
+		return ansiReset
+	}
+	return app.rules[i].style
+}
+
+// startBlinking animates the rows matching a "blink" rule, as long as there are some
+func (app *monitorApp) startBlinking() {
+	if app.blinking || !app.hasBlinkingAlert() {
+		return
+	}
+	app.blinking = true
+	var tick func()
+	tick = func() {
+		app.gui.Update(func(_ *gocui.Gui) error {
+			if !app.hasBlinkingAlert() {
+				app.blinking, app.blinkOn = false, false
+				return nil
+			}
+			app.blinkOn = !app.blinkOn
+			app.renderList()
+			app.redrawTable()
+			time.AfterFunc(blinkPeriod, tick)
+			return nil
+		})
+	}
+	time.AfterFunc(blinkPeriod, tick)
+}
+
+func (app *monitorApp) hasBlinkingAlert() bool {
+	for _, state := range app.alerts {
+		for _, i := range state.rules {
+			if app.rules[i].Style == "blink" {
+				return true
+			}
+		}
+	}
+	return false
+}
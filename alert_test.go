@@ -0,0 +1,80 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import "testing"
+
+func TestParseAlertRule(t *testing.T) {
+	cases := []struct {
+		rule  string
+		want  AlertRule
+		fails bool
+	}{
+		{rule: "temperature > 70 -> red", want: AlertRule{"temperature", ">", "70", "red"}},
+		{rule: "temperature>=70->bold", want: AlertRule{"temperature", ">=", "70", "bold"}},
+		{rule: "state != ok → yellow", want: AlertRule{"state", "!=", "ok", "yellow"}},
+		{rule: "cmd ~ a==b -> red", want: AlertRule{"cmd", "~", "a==b", "red"}},
+		{rule: "cmd ~ ^x->y$ -> blink", want: AlertRule{"cmd", "~", "^x->y$", "blink"}},
+		{rule: "size <= 3 -> cyan", want: AlertRule{"size", "<=", "3", "cyan"}},
+		{rule: "name == a<b -> green", want: AlertRule{"name", "==", "a<b", "green"}},
+		{rule: "temperature > 70", fails: true},
+		{rule: "temperature 70 -> red", fails: true},
+		{rule: "temperature = 70 -> red", fails: true},
+		{rule: "> 70 -> red", fails: true},
+		{rule: "temperature > 70 -> pink", fails: true},
+		{rule: "cmd ~ ( -> red", fails: true},
+	}
+	for _, c := range cases {
+		got, err := ParseAlertRule(c.rule)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %+v", c.rule, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.rule, err)
+		} else if got != c.want {
+			t.Errorf("%q: got %+v, expected %+v", c.rule, got, c.want)
+		}
+	}
+}
+
+func TestAlertRuleMatches(t *testing.T) {
+	cases := []struct {
+		rule  string
+		value string
+		want  bool
+	}{
+		{"v > 70 -> red", "71", true},
+		{"v > 70 -> red", "9", false},
+		{"v > 70 -> red", "abc", false},
+		{"v > 70 -> red", "N/A", false},
+		{"v != 70 -> red", "N/A", false},
+		{"v < 70 -> red", "", false},
+		{"v > 70 -> red", " 80 ", true},
+		{"v > abc -> red", "abd", true},
+		{"v == ok -> red", "ok", true},
+		{"v != ok -> red", "ok", false},
+		{"v ~ ^er+ -> red", "error", true},
+		{"v <= 1.5 -> red", "1.5", true},
+	}
+	for _, c := range cases {
+		rule, err := ParseAlertRule(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compiled, _ := compileAlertRule(rule)
+		if got := compiled.matches(testItem("x", "v", c.value)); got != c.want {
+			t.Errorf("%q on %q: %v", c.rule, c.value, got)
+		}
+	}
+	rule, _ := ParseAlertRule("missing > 1 -> red")
+	compiled, _ := compileAlertRule(rule)
+	if compiled.matches(testItem("x", "v", "2")) {
+		t.Error("an item lacking the key shouldn't match")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+// clipboardCommands are the tools tried in turn to copy a text
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"pbcopy"},
+}
+
+// copyToClipboard copies a text with the first clipboard tool available, or with the OSC 52 escape
+// sequence understood by most terminal emulators, even through SSH. It runs in the GUI loop.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		return nil
+	}
+	return writeOSC52(text)
+}
+
+// writeOSC52 sends the OSC 52 sequence to the terminal used by termbox, between two of its flushes, then
+// redraws the whole screen in case the terminal displayed anything.
+func writeOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("clipboard: %w", err)
+	}
+	_, err = fmt.Fprintf(tty, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	if closeErr := tty.Close(); err == nil {
+		err = closeErr
+	}
+	if syncErr := termbox.Sync(); err == nil {
+		err = syncErr
+	}
+	if err != nil {
+		return fmt.Errorf("clipboard: %w", err)
+	}
+	return nil
+}
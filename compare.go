@@ -0,0 +1,286 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// diffContext is the number of unchanged lines around the changes of the unified diff
+	diffContext = 3
+	// diffMaxLines bounds the size of the details compared line by line
+	diffMaxLines = 2000
+)
+
+// diffOp is a line of a diff: kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// toggleBase marks the selected item as the base of the comparisons, or unmarks it
+func (app *monitorApp) toggleBase() {
+	current := app.selectedItem()
+	if current == nil {
+		return
+	}
+	if app.base != nil && primaryValue(app.base) == primaryValue(current) {
+		app.base = nil
+		if app.mode == modeCompare {
+			app.setMode(modeDetail)
+		}
+		return
+	}
+	app.base = current
+	app.setMode(modeCompare)
+}
+
+// currentBase returns the latest version of the base item, and if it is still listed
+func (app *monitorApp) currentBase() (MonitoredItem, bool) {
+	key := primaryValue(app.base)
+	for _, item := range app.items {
+		if primaryValue(item) == key {
+			return item, true
+		}
+	}
+	return app.base, false
+}
+
+// redrawCompare renders the differences between the base item and the selected one
+func (app *monitorApp) redrawCompare() {
+	app.panelDetail.Clear()
+	current := app.selectedItem()
+	if app.base == nil {
+		app.panelDetail.Title = "Compare"
+		fmt.Fprintln(app.panelDetail, "No base item, mark one with Alt-b")
+		return
+	}
+	base, listed := app.currentBase()
+	app.panelDetail.Title = fmt.Sprintf("Compare %s vs ", primaryValue(base))
+	if current == nil {
+		return
+	}
+	app.panelDetail.Title += primaryValue(current)
+
+	var b strings.Builder
+	if !listed {
+		fmt.Fprintf(&b, "%s(the base is not listed anymore)%s\n\n", ansiRed, ansiReset)
+	}
+	writeKeysDiff(&b, base, current)
+	fmt.Fprintf(&b, "\n\n%sDetail%s\n", ansiBold, ansiReset)
+	writeUnifiedDiff(&b, base.GetDetail(), current.GetDetail())
+	app.writeDetailText(b.String())
+}
+
+// writeKeysDiff writes the keys of both items with their values, colored when they differ
+func writeKeysDiff(w io.Writer, base, current MonitoredItem) {
+	keys := append([]string{}, base.GetKeys()...)
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		seen[k] = true
+	}
+	for _, k := range current.GetKeys() {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+
+	rows := [][]tableCell{{{text: "  key"}, {text: "base"}, {text: "current"}}}
+	for _, k := range keys {
+		inBase, inCurrent := hasKey(base, k), hasKey(current, k)
+		before, after := base.GetValue(k), current.GetValue(k)
+		mark, style := " ", ""
+		switch {
+		case !inCurrent:
+			mark, style, after = "-", ansiRed, ""
+		case !inBase:
+			mark, style, before = "+", ansiGreen, ""
+		case before != after:
+			mark, style = "~", ansiYellow
+		}
+		rows = append(rows, []tableCell{
+			{text: mark + " " + k, style: style},
+			{text: before, style: style},
+			{text: after, style: style},
+		})
+	}
+	writeTable(w, rows)
+}
+
+// diffLines computes the shortest edition from a to b, with the linear space variant of the Myers algorithm
+func diffLines(a, b []string) []diffOp {
+	return appendDiff(make([]diffOp, 0, len(a)+len(b)), a, b)
+}
+
+// appendDiff appends the edition from a to b: the common prefix and suffix are kept, and the lines in between
+// are compared on both sides of a point of a shortest edition.
+func appendDiff(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, text := range b {
+			ops = append(ops, diffOp{'+', text})
+		}
+	case len(b) == 0:
+		for _, text := range a {
+			ops = append(ops, diffOp{'-', text})
+		}
+	default:
+		x, y := middleSnake(a, b)
+		ops = appendDiff(ops, a[:x], b[:y])
+		ops = appendDiff(ops, a[x:], b[y:])
+	}
+	for _, text := range common {
+		ops = append(ops, diffOp{' ', text})
+	}
+	return ops
+}
+
+// middleSnake returns a point of a shortest edition from a to b, where the paths searched from both ends meet.
+// a and b are not empty, and differ on their first and last lines.
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset, size := maxD, 2*maxD+2
+	// forward[k] is the furthest x reached from the start on the diagonal x-y=k, backward[k] the same from
+	// the end on the reversed texts
+	forward, backward := make([]int, size), make([]int, size)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	odd := delta%2 != 0
+	// The diagonals leaving the grid are not explored anymore
+	kStart, kEnd, rStart, rEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + kStart; k <= d-kEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				kEnd += 2
+			case y > m:
+				kStart += 2
+			case odd:
+				if r := offset + delta - k; r >= 0 && r < size && backward[r] != -1 && x >= n-backward[r] {
+					return x, y
+				}
+			}
+		}
+		for k := -d + rStart; k <= d-rEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x, y = x+1, y+1
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !odd:
+				if f := offset + delta - k; f >= 0 && f < size && forward[f] != -1 && forward[f] >= n-x {
+					return forward[f], forward[f] - (f - offset)
+				}
+			}
+		}
+	}
+	// Not reached, the paths always meet: remove all the lines then add all of them
+	return n, 0
+}
+
+// writeUnifiedDiff writes the changes from a text to another, with their context, grouped in hunks
+func writeUnifiedDiff(w io.Writer, before, after string) {
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+	if len(a) > diffMaxLines || len(b) > diffMaxLines {
+		fmt.Fprintln(w, "(too large to compare)")
+		return
+	}
+	ops := diffLines(a, b)
+
+	// Mark the lines to print: the changes and their context
+	shown := make([]bool, len(ops))
+	changed := false
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		changed = true
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(ops) {
+				shown[j] = true
+			}
+		}
+	}
+	if !changed {
+		fmt.Fprintln(w, "(identical)")
+		return
+	}
+
+	lineA, lineB := 1, 1
+	for i := 0; i < len(ops); {
+		if !shown[i] {
+			lineA, lineB = lineA+1, lineB+1
+			i++
+			continue
+		}
+		end := i
+		countA, countB := 0, 0
+		for ; end < len(ops) && shown[end]; end++ {
+			if ops[end].kind != '+' {
+				countA++
+			}
+			if ops[end].kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(w, "%s@@ -%d,%d +%d,%d @@%s\n", ansiCyan, lineA, countA, lineB, countB, ansiReset)
+		for ; i < end; i++ {
+			switch ops[i].kind {
+			case '-':
+				fmt.Fprintf(w, "%s-%s%s\n", ansiRed, ops[i].text, ansiReset)
+			case '+':
+				fmt.Fprintf(w, "%s+%s%s\n", ansiGreen, ops[i].text, ansiReset)
+			default:
+				fmt.Fprintf(w, " %s\n", ops[i].text)
+			}
+		}
+		lineA, lineB = lineA+countA, lineB+countB
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// lcsLength is the reference length of the longest common subsequence
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = maxInt(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkDiff checks that the edition rebuilds both texts and is the shortest one
+func checkDiff(t *testing.T, a, b []string) {
+	t.Helper()
+	ops := diffLines(a, b)
+	var gotA, gotB []string
+	edits := 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			gotA = append(gotA, op.text)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.text)
+		}
+		if op.kind != ' ' {
+			edits++
+		}
+	}
+	if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("%q -> %q: the edition %v does not rebuild the texts", a, b, ops)
+	}
+	if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+		t.Fatalf("%q -> %q: %d edits instead of %d", a, b, edits, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	cases := [][2]string{
+		{"", ""},
+		{"a", ""},
+		{"", "a"},
+		{"a b c", "a b c"},
+		{"a b c", "a x c"},
+		{"a b c d", "b c d e"},
+		{"x a b c", "a b c y"},
+		{"a b c a b b a", "c b a b a c"},
+		{"a a a", "a"},
+		{"a b", "b a"},
+	}
+	for _, c := range cases {
+		checkDiff(t, strings.Fields(c[0]), strings.Fields(c[1]))
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		out := make([]string, rng.Intn(30))
+		for i := range out {
+			out[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return out
+	}
+	for i := 0; i < 500; i++ {
+		checkDiff(t, random(), random())
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, diffMaxLines)
+	for i := range a {
+		a[i] = strings.Repeat("x", i%7) + string(rune('a'+i%26))
+	}
+	b := append(append([]string{"new"}, a[:1000]...), a[1001:]...)
+	ops := diffLines(a, b)
+	if len(ops) != diffMaxLines+1 {
+		t.Fatalf("%d operations instead of %d", len(ops), diffMaxLines+1)
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		before, after string
+		want          []string
+	}{
+		{"a\nb\n", "a\nb\n", []string{"(identical)"}},
+		{"1\n2\n3\n4\n5\n6\n7\n8\n9\n", "1\n2\n3\n4\nfive\n6\n7\n8\n9\n", []string{
+			ansiCyan + "@@ -2,7 +2,7 @@" + ansiReset, " 2", " 3", " 4",
+			ansiRed + "-5" + ansiReset, ansiGreen + "+five" + ansiReset, " 6", " 7", " 8",
+		}},
+		{"a\n", "a\nb\n", []string{ansiCyan + "@@ -1,1 +1,2 @@" + ansiReset, " a", ansiGreen + "+b" + ansiReset}},
+	}
+	for _, c := range cases {
+		var b strings.Builder
+		writeUnifiedDiff(&b, c.before, c.after)
+		if got, want := b.String(), strings.Join(c.want, "\n")+"\n"; got != want {
+			t.Errorf("%q -> %q: got\n%s\nexpected\n%s", c.before, c.after, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+// QueryCompleter is an optional interface of a Monitorable that helps typing the query.
+type QueryCompleter interface {
+	// CompleteQuery returns the queries starting with the text typed before the cursor of the Query panel.
+	CompleteQuery(prefix string) ([]string, error)
+}
+
+// completeQuery completes the text before the cursor of the Query panel: a single candidate is applied at once,
+// several ones are proposed in a popup under the panel.
+func (app *monitorApp) completeQuery() {
+	completer, ok := app.source.(QueryCompleter)
+	if !ok {
+		return
+	}
+	text := strings.TrimRight(app.panelQuery.Buffer(), "\r\n")
+	pos := byteOffset(text, app.inputPosition(app.panelQuery))
+	prefix, suffix := text[:pos], text[pos:]
+
+	candidates, err := completer.CompleteQuery(prefix)
+	app.err = err
+	apply := func(candidate string) {
+		setInput(app.panelQuery, candidate+suffix)
+		placeCursor(app.panelQuery, utf8.RuneCountInString(candidate))
+	}
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		apply(candidates[0])
+	default:
+		app.openChoice("Completions", app.panelQuery, candidates, apply)
+	}
+}
+
+// inputPosition returns the position of the cursor in the runes of the text of an input panel
+func (app *monitorApp) inputPosition(v *gocui.View) int {
+	cx, _ := v.Cursor()
+	ox, _ := v.Origin()
+	return cx + ox
+}
+
+func (app *monitorApp) bindCompletionKeys() {
+	err := app.gui.SetKeybinding(app.panelQuery.Name(), gocui.KeyCtrlSpace, gocui.ModNone,
+		func(_ *gocui.Gui, _ *gocui.View) error {
+			app.completeQuery()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// StructuredDetail is an optional interface of a MonitoredItem that exposes its detail as a tree of values
+// rather than as a text. The tree is any value that encoding/json can marshal (maps, slices, structs, scalars)
+// and cui renders it itself, in the format chosen at runtime. GetDetail remains used for the other items.
+type StructuredDetail interface {
+	GetStructuredDetail() interface{}
+}
+
+type detailFormat int
+
+const (
+	formatJSON detailFormat = iota
+	formatYAML
+	formatKeyValue
+)
+
+func (f detailFormat) String() string {
+	switch f {
+	case formatYAML:
+		return "yaml"
+	case formatKeyValue:
+		return "key/value"
+	default:
+		return "json"
+	}
+}
+
+const (
+	styleKey     = ansiCyan
+	styleString  = ansiGreen
+	styleNumber  = ansiYellow
+	styleLiteral = ansiMagenta
+)
+
+// detailField is a member of an object in the normalized detail tree, that keeps the order of the fields
+type detailField struct {
+	key   string
+	value interface{}
+}
+
+// detailObject is an object of the normalized tree. The arrays are []interface{}, the scalars are
+// string, json.Number, bool or nil.
+type detailObject []detailField
+
+// normalizeDetail turns a value into a tree of detailObject, []interface{} and scalars, by the means of its
+// JSON encoding so that the field tags and the custom marshalers are honored.
+func normalizeDetail(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+	decoder.UseNumber()
+	return decodeDetail(decoder)
+}
+
+func decodeDetail(decoder *json.Decoder) (interface{}, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := detailObject{}
+		for decoder.More() {
+			k, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeDetail(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, detailField{key: k.(string), value: v})
+		}
+		_, err = decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for decoder.More() {
+			v, err := decodeDetail(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = decoder.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+// writeDetail renders the detail of an item, structured in the given format if the item permits it
+func writeDetail(w io.Writer, item MonitoredItem, format detailFormat) {
+	sd, ok := item.(StructuredDetail)
+	if !ok {
+		fmt.Fprintf(w, "%v", item.GetDetail())
+		return
+	}
+	tree, err := normalizeDetail(sd.GetStructuredDetail())
+	if err != nil {
+		fmt.Fprintf(w, "%v", item.GetDetail())
+		return
+	}
+	switch format {
+	case formatYAML:
+		writeYAML(w, tree, 0, false)
+	case formatKeyValue:
+		tw := tabwriter.NewWriter(w, 8, 1, 2, ' ', 0)
+		writeKeyValue(tw, "", tree)
+		tw.Flush()
+	default:
+		writeJSON(w, tree, 0)
+		fmt.Fprintln(w)
+	}
+}
+
+// colorScalar renders a scalar with the color of its type, as JSON or as YAML
+func colorScalar(v interface{}, yaml bool) string {
+	switch tv := v.(type) {
+	case nil:
+		return styleLiteral + "null" + ansiReset
+	case bool:
+		return styleLiteral + strconv.FormatBool(tv) + ansiReset
+	case json.Number:
+		return styleNumber + tv.String() + ansiReset
+	case string:
+		if yaml && !yamlNeedsQuotes(tv) {
+			return styleString + tv + ansiReset
+		}
+		return styleString + strconv.Quote(tv) + ansiReset
+	default:
+		return fmt.Sprint(tv)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}, depth int) {
+	indent := strings.Repeat(" ", depth+1)
+	switch tv := v.(type) {
+	case detailObject:
+		if len(tv) == 0 {
+			fmt.Fprint(w, "{}")
+			return
+		}
+		fmt.Fprintln(w, "{")
+		for i, f := range tv {
+			fmt.Fprintf(w, "%s%s%q%s: ", indent, styleKey, f.key, ansiReset)
+			writeJSON(w, f.value, depth+1)
+			if i < len(tv)-1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s}", indent[1:])
+	case []interface{}:
+		if len(tv) == 0 {
+			fmt.Fprint(w, "[]")
+			return
+		}
+		fmt.Fprintln(w, "[")
+		for i, elt := range tv {
+			fmt.Fprint(w, indent)
+			writeJSON(w, elt, depth+1)
+			if i < len(tv)-1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s]", indent[1:])
+	default:
+		fmt.Fprint(w, colorScalar(tv, false))
+	}
+}
+
+// writeYAML renders a value at the given depth. When inline, the first line goes after a "- " already written.
+func writeYAML(w io.Writer, v interface{}, depth int, inline bool) {
+	indent := strings.Repeat("  ", depth)
+	switch tv := v.(type) {
+	case detailObject:
+		if len(tv) == 0 {
+			fmt.Fprintf(w, "%s{}\n", indentUnless(inline, indent))
+			return
+		}
+		for i, f := range tv {
+			fmt.Fprintf(w, "%s%s%s%s:", indentUnless(inline && i == 0, indent), styleKey, f.key, ansiReset)
+			writeYAMLValue(w, f.value, depth)
+		}
+	case []interface{}:
+		if len(tv) == 0 {
+			fmt.Fprintf(w, "%s[]\n", indentUnless(inline, indent))
+			return
+		}
+		for i, elt := range tv {
+			fmt.Fprintf(w, "%s- ", indentUnless(inline && i == 0, indent))
+			if isScalar(elt) {
+				fmt.Fprintln(w, colorScalar(elt, true))
+			} else {
+				writeYAML(w, elt, depth+1, true)
+			}
+		}
+	default:
+		fmt.Fprintf(w, "%s%s\n", indentUnless(inline, indent), colorScalar(tv, true))
+	}
+}
+
+// writeYAMLValue renders the value of a field, after its key
+func writeYAMLValue(w io.Writer, v interface{}, depth int) {
+	switch tv := v.(type) {
+	case detailObject:
+		if len(tv) == 0 {
+			fmt.Fprintln(w, " {}")
+			return
+		}
+		fmt.Fprintln(w)
+		writeYAML(w, tv, depth+1, false)
+	case []interface{}:
+		if len(tv) == 0 {
+			fmt.Fprintln(w, " []")
+			return
+		}
+		fmt.Fprintln(w)
+		writeYAML(w, tv, depth+1, false)
+	default:
+		fmt.Fprintf(w, " %s\n", colorScalar(tv, true))
+	}
+}
+
+func indentUnless(inline bool, indent string) string {
+	if inline {
+		return ""
+	}
+	return indent
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case detailObject, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+// yamlNeedsQuotes tells if a string would be misread as plain YAML scalar
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t")
+}
+
+// writeKeyValue renders the leaves of the tree, one per line, with their path from the root
+func writeKeyValue(w io.Writer, path string, v interface{}) {
+	switch tv := v.(type) {
+	case detailObject:
+		for _, f := range tv {
+			sub := f.key
+			if path != "" {
+				sub = path + "." + f.key
+			}
+			writeKeyValue(w, sub, f.value)
+		}
+	case []interface{}:
+		for i, elt := range tv {
+			writeKeyValue(w, fmt.Sprintf("%s[%d]", path, i), elt)
+		}
+	default:
+		fmt.Fprintf(w, "%s%s%s:\t%s\n", styleKey, path, ansiReset, colorScalar(tv, false))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// renderDetail renders a JSON document with a writer of the detail panel, without the colors
+func renderDetail(t *testing.T, doc string, write func(b *strings.Builder, tree interface{})) string {
+	t.Helper()
+	tree, err := normalizeDetail(json.RawMessage(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	write(&b, tree)
+	return ansiRegexp.ReplaceAllString(b.String(), "")
+}
+
+func TestWriteYAML(t *testing.T) {
+	cases := []struct {
+		doc  string
+		want string
+	}{
+		{`{}`, "{}\n"},
+		{`[]`, "[]\n"},
+		{`"x"`, "x\n"},
+		{`{"name":"x","n":null,"ok":true,"size":12}`, "name: x\nn: null\nok: true\nsize: 12\n"},
+		{`{"empty":{},"none":[]}`, "empty: {}\nnone: []\n"},
+		{`{"items":[{"a":1,"b":[]},{"c":{}}]}`, "items:\n  - a: 1\n    b: []\n  - c: {}\n"},
+		{`{"m":[[1,2],[],[{"k":"v"}]]}`, "m:\n  - - 1\n    - 2\n  - []\n  - - k: v\n"},
+		{`{"o":{"p":{"q":[1]}}}`, "o:\n  p:\n    q:\n      - 1\n"},
+		{`[{"a":{"b":1}}]`, "- a:\n    b: 1\n"},
+	}
+	for _, c := range cases {
+		got := renderDetail(t, c.doc, func(b *strings.Builder, tree interface{}) { writeYAML(b, tree, 0, false) })
+		if got != c.want {
+			t.Errorf("%s: got\n%s\nexpected\n%s", c.doc, got, c.want)
+		}
+	}
+}
+
+func TestYAMLNeedsQuotes(t *testing.T) {
+	cases := []struct {
+		s    string
+		want bool
+	}{
+		{"plain", false},
+		{"two words", false},
+		{"a:b", false},
+		{"x#y", false},
+		{"", true},
+		{" pad", true},
+		{"pad ", true},
+		{"12", true},
+		{"1.5e3", true},
+		{"-3", true},
+		{"true", true},
+		{"No", true},
+		{"null", true},
+		{"~", true},
+		{"a: b", true},
+		{"x #y", true},
+		{"#c", true},
+		{"- item", true},
+		{"{x}", true},
+		{"*ref", true},
+		{"'q'", true},
+		{"line\nbreak", true},
+	}
+	for _, c := range cases {
+		if got := yamlNeedsQuotes(c.s); got != c.want {
+			t.Errorf("%q: %v", c.s, got)
+		}
+	}
+
+	got := renderDetail(t, `["12","true","a: b","plain"]`,
+		func(b *strings.Builder, tree interface{}) { writeYAML(b, tree, 0, false) })
+	if want := "- \"12\"\n- \"true\"\n- \"a: b\"\n- plain\n"; got != want {
+		t.Errorf("got\n%s", got)
+	}
+}
+
+func TestWriteKeyValue(t *testing.T) {
+	got := renderDetail(t, `{"a":{"b":[1,{"c":"x y"}]},"d":null,"e":"12"}`,
+		func(b *strings.Builder, tree interface{}) { writeKeyValue(b, "", tree) })
+	want := "a.b[0]:\t1\na.b[1].c:\t\"x y\"\nd:\tnull\ne:\t\"12\"\n"
+	if got != want {
+		t.Errorf("got\n%q\nexpected\n%q", got, want)
+	}
+}
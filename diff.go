@@ -0,0 +1,139 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// changeKind tells how an item changed between two fetches
+type changeKind int
+
+const (
+	changeNone changeKind = iota
+	changeAdded
+	changeModified
+	changeRemoved
+)
+
+// removedItem is the ghost of an item that disappeared since the previous fetch, it is displayed for one cycle.
+// When compared to a baseline, the ghosts of the items absent from the current fetch are displayed at each cycle.
+type removedItem struct {
+	MonitoredItem
+	since string
+}
+
+func (ri *removedItem) GetDetail() string {
+	return "(removed since " + ri.since + ")\n\n" + ri.MonitoredItem.GetDetail()
+}
+
+// indexItems indexes the items by primary key value, the ghosts of removed items are ignored.
+func indexItems(items []MonitoredItem) map[string]MonitoredItem {
+	index := make(map[string]MonitoredItem, len(items))
+	for _, item := range items {
+		if _, ok := item.(*removedItem); !ok {
+			index[primaryValue(item)] = item
+		}
+	}
+	return index
+}
+
+// changedKeys lists the keys whose value differs between two states of an item, in alphabetical order
+func changedKeys(former, current MonitoredItem) []string {
+	keys := make(map[string]bool)
+	for _, k := range former.GetKeys() {
+		keys[k] = true
+	}
+	for _, k := range current.GetKeys() {
+		keys[k] = true
+	}
+	out := make([]string, 0)
+	for k := range keys {
+		if former.GetValue(k) != current.GetValue(k) {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// diffItems compares the fetched items with the items of the reference, if any.
+// It returns the ghosts of the items that disappeared, to be displayed for one cycle.
+func (app *monitorApp) diffItems() []MonitoredItem {
+	app.changes = make(map[string]changeKind)
+	ghosts := make([]MonitoredItem, 0)
+	reference := app.reference()
+	if reference == nil {
+		return ghosts
+	}
+
+	seen := make(map[string]bool, len(app.items))
+	for _, item := range app.items {
+		key := primaryValue(item)
+		seen[key] = true
+		if former, ok := reference[key]; !ok {
+			app.changes[key] = changeAdded
+		} else if len(changedKeys(former, item)) > 0 {
+			app.changes[key] = changeModified
+		}
+	}
+	for key, former := range reference {
+		if !seen[key] {
+			app.changes[key] = changeRemoved
+			ghosts = append(ghosts, &removedItem{former, app.referenceName()})
+		}
+	}
+	return ghosts
+}
+
+// previousOf returns the state of the item in the reference, or nil
+func (app *monitorApp) previousOf(item MonitoredItem) MonitoredItem {
+	if _, ok := item.(*removedItem); ok || app.reference() == nil {
+		return nil
+	}
+	return app.reference()[primaryValue(item)]
+}
+
+// changeStyle returns the ANSI style of a row according to the last fetch
+func (app *monitorApp) changeStyle(item MonitoredItem) string {
+	if _, ok := item.(*removedItem); ok {
+		return ansiRed
+	}
+	switch app.changes[primaryValue(item)] {
+	case changeAdded:
+		return ansiGreen
+	case changeModified:
+		return ansiYellow
+	default:
+		return ""
+	}
+}
+
+// cellStyle returns the ANSI style of a value in the table, according to the reference
+func (app *monitorApp) cellStyle(former MonitoredItem, k, value string) string {
+	if former != nil && former.GetValue(k) != value {
+		return ansiYellow + ansiBold
+	}
+	return ""
+}
+
+// writeChanges lists the values of the item that changed since the reference
+func (app *monitorApp) writeChanges(w io.Writer, item MonitoredItem) {
+	former := app.previousOf(item)
+	if former == nil {
+		return
+	}
+	keys := changedKeys(former, item)
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%sChanged since %s:%s\n", ansiBold, app.referenceName(), ansiReset)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s: %s -> %s%s\n", ansiYellow, k, former.GetValue(k), item.GetValue(k), ansiReset)
+	}
+}
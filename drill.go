@@ -0,0 +1,201 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// childrenTimeout bounds the fetch of the children of a LazyParentItem
+const childrenTimeout = 10 * time.Second
+
+// ParentItem is an optional interface of a MonitoredItem that holds nested items, e.g. a host has disks and a disk
+// has partitions. Pressing Enter on such an item in the list descends into its children, Backspace goes back up.
+type ParentItem interface {
+	MonitoredItem
+
+	// Children returns the nested items. When the item also implements HasChildren like a LazyParentItem, it is
+	// used to tell if the item is a parent without building its children.
+	Children() []MonitoredItem
+}
+
+// LazyParentItem is an optional interface of a MonitoredItem whose nested items are fetched on demand, when the
+// user descends into the item.
+type LazyParentItem interface {
+	MonitoredItem
+
+	// HasChildren tells if the item may have children, without fetching them
+	HasChildren() bool
+
+	// FetchChildren returns the nested items, within the delay of the context
+	FetchChildren(ctx context.Context) ([]MonitoredItem, error)
+}
+
+// level saves the state of the list when descending into the children of an item
+type level struct {
+	parent MonitoredItem
+
+	items        []MonitoredItem
+	knownKeys    map[string]bool
+	possibleKeys []string
+	rowFilter    *rowFilter
+	previous     map[string]MonitoredItem
+	changes      map[string]changeKind
+	history      map[string]itemHistory
+	origin       int
+	cursor       int
+}
+
+// isParent tells if an item has children to descend into
+func isParent(item MonitoredItem) bool {
+	if parent, ok := item.(interface{ HasChildren() bool }); ok {
+		return parent.HasChildren()
+	}
+	if parent, ok := item.(ParentItem); ok {
+		return len(parent.Children()) > 0
+	}
+	return false
+}
+
+func fetchChildren(item MonitoredItem) ([]MonitoredItem, error) {
+	switch parent := item.(type) {
+	case ParentItem:
+		return parent.Children(), nil
+	case LazyParentItem:
+		ctx, cancel := context.WithTimeout(context.Background(), childrenTimeout)
+		defer cancel()
+		return parent.FetchChildren(ctx)
+	default:
+		return nil, nil
+	}
+}
+
+// descend replaces the list by the children of the selected item, it returns false if the item has no children
+func (app *monitorApp) descend() bool {
+	item := app.selectedItem()
+	if item == nil || !isParent(item) {
+		return false
+	}
+	if _, ok := item.(*removedItem); ok {
+		return false
+	}
+	app.descendInto(item)
+	app.refreshList(nil)
+	app.selectIndex(0)
+	app.redrawTable()
+	app.redrawDetail()
+	return true
+}
+
+func (app *monitorApp) descendInto(item MonitoredItem) {
+	// The stream and the watch concern the top level
+	app.stopStream()
+	app.cancelWatch()
+
+	children, err := fetchChildren(item)
+	app.err = err
+
+	_, oy := app.panelList.Origin()
+	_, cy := app.panelList.Cursor()
+	app.levels = append(app.levels, level{
+		parent:       item,
+		items:        app.items,
+		knownKeys:    app.knownKeys,
+		possibleKeys: app.possibleKeys,
+		rowFilter:    app.rowFilter,
+		previous:     app.previous,
+		changes:      app.changes,
+		history:      app.history,
+		origin:       oy,
+		cursor:       cy,
+	})
+
+	app.items = make([]MonitoredItem, 0, len(children))
+	app.items = append(app.items, children...)
+	app.knownKeys = make(map[string]bool)
+	app.possibleKeys = make([]string, 0)
+	app.rowFilter = nil
+	app.previous, app.changes, app.history = nil, nil, nil
+	app.addPossibleKeys(app.items)
+	sort.Slice(app.items, func(idx0, idx1 int) bool { return app.lessItems(app.items[idx0], app.items[idx1]) })
+	app.recordHistory()
+	app.evaluateAlerts()
+}
+
+// ascend restores the list of the parent level, it returns false at the top level
+func (app *monitorApp) ascend() bool {
+	if len(app.levels) == 0 {
+		return false
+	}
+	lvl := app.popLevel()
+	app.refreshList(nil)
+	if err := app.panelList.SetOrigin(0, lvl.origin); err != nil {
+		app.selectIndex(0)
+	} else if err = app.panelList.SetCursor(0, lvl.cursor); err != nil {
+		app.selectIndex(0)
+	}
+	app.redrawTable()
+	app.redrawDetail()
+
+	if watchable, ok := app.source.(WatchableMonitorable); ok && len(app.levels) == 0 {
+		app.startWatch(watchable)
+	}
+	return true
+}
+
+func (app *monitorApp) popLevel() level {
+	lvl := app.levels[len(app.levels)-1]
+	app.levels = app.levels[:len(app.levels)-1]
+	app.items = lvl.items
+	app.knownKeys = lvl.knownKeys
+	app.possibleKeys = lvl.possibleKeys
+	app.rowFilter = lvl.rowFilter
+	app.previous, app.changes, app.history = lvl.previous, lvl.changes, lvl.history
+	return lvl
+}
+
+// levelPath returns the primary key values of the items descended into
+func (app *monitorApp) levelPath() []string {
+	path := make([]string, 0, len(app.levels))
+	for _, lvl := range app.levels {
+		path = append(path, primaryValue(lvl.parent))
+	}
+	return path
+}
+
+// resetLevels goes back to the top level and returns the path that was left
+func (app *monitorApp) resetLevels() []string {
+	path := app.levelPath()
+	for len(app.levels) > 0 {
+		app.popLevel()
+	}
+	return path
+}
+
+// redescend follows the path left by resetLevels after a refresh, as far as the items are still present
+func (app *monitorApp) redescend(path []string) {
+	for _, pk := range path {
+		var found MonitoredItem
+		for _, item := range app.items {
+			if _, ok := item.(*removedItem); !ok && primaryValue(item) == pk && isParent(item) {
+				found = item
+				break
+			}
+		}
+		if found == nil {
+			return
+		}
+		app.descendInto(found)
+	}
+}
+
+// breadcrumb renders the path of the items descended into, for the title of the list
+func (app *monitorApp) breadcrumb() string {
+	return strings.Join(app.levelPath(), " > ")
+}
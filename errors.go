@@ -0,0 +1,78 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxReportedErrors bounds the number of errors detailed in a partial error, the Error panel is tiny.
+const maxReportedErrors = 3
+
+// PartialError reports the errors that occurred on a subset of the items of a fetch.
+// A Monitorable returns it along with the items that could be fetched, that are still displayed.
+type PartialError struct {
+	What   string
+	Errors []error
+}
+
+func (pe *PartialError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", len(pe.Errors), pe.What)
+	for i, err := range pe.Errors {
+		if i >= maxReportedErrors {
+			b.WriteString("\n...")
+			break
+		}
+		b.WriteString("\n")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the first error
+func (pe *PartialError) Unwrap() error {
+	if len(pe.Errors) == 0 {
+		return nil
+	}
+	return pe.Errors[0]
+}
+
+// joinErrors builds a PartialError, or returns nil if there is no error.
+func joinErrors(what string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PartialError{What: what, Errors: errs}
+}
+
+// fitErrorLines lays out the errors in the given number of lines. Each error keeps at least one line, the lines
+// of an error cut short being joined on its last one.
+func fitErrorLines(height int, errs ...error) []string {
+	texts := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			texts = append(texts, err.Error())
+		}
+	}
+	lines := make([]string, 0, height)
+	for i, text := range texts {
+		parts := strings.Split(text, "\n")
+		room := height - len(lines) - (len(texts) - i - 1)
+		if room < 1 {
+			room = 1
+		}
+		if len(parts) > room {
+			parts = append(parts[:room-1], strings.Join(parts[room-1:], " | "))
+		}
+		lines = append(lines, parts...)
+	}
+	if height > 0 && len(lines) > height {
+		lines = append(lines[:height-1], strings.Join(lines[height-1:], " | "))
+	}
+	return lines
+}
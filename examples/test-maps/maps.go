@@ -54,6 +54,8 @@ func (mi *mapItem) GetDetail() string {
 	return builder.String()
 }
 
+func (mi *mapItem) GetStructuredDetail() interface{} { return map[string]string(*mi) }
+
 func (dl *staticMapsSource) FetchAll(query string) ([]cui.MonitoredItem, error) {
 	var out []cui.MonitoredItem
 	if query == "" {
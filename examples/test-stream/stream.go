@@ -0,0 +1,79 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfsmig/cui"
+)
+
+func main() {
+	if err := cui.Monitor(&slowSource{}, "50000"); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// slowSource generates as many items as requested in the query, by small batches
+type slowSource struct{}
+
+type numberItem struct {
+	id    string
+	value string
+}
+
+func (ni *numberItem) GetPrimaryKey() string { return "id" }
+
+func (ni *numberItem) GetKeys() []string { return []string{"id", "value"} }
+
+func (ni *numberItem) GetValue(k string) string {
+	switch k {
+	case "id":
+		return ni.id
+	case "value":
+		return ni.value
+	default:
+		return "-"
+	}
+}
+
+func (ni *numberItem) GetDetail() string {
+	return strings.Join([]string{"id: " + ni.id, "value: " + ni.value}, "\n")
+}
+
+func (src *slowSource) FetchAll(query string) ([]cui.MonitoredItem, error) {
+	return nil, errors.New("streaming only")
+}
+
+func (src *slowSource) FetchStream(ctx context.Context, query string, out chan<- []cui.MonitoredItem) error {
+	total, err := strconv.Atoi(strings.TrimSpace(query))
+	if err != nil {
+		return err
+	}
+	const batchSize = 500
+	for sent := 0; sent < total; sent += batchSize {
+		batch := make([]cui.MonitoredItem, 0, batchSize)
+		for i := sent; i < sent+batchSize && i < total; i++ {
+			batch = append(batch, &numberItem{
+				id:    strconv.FormatUint(rand.Uint64(), 16),
+				value: strconv.Itoa(i),
+			})
+		}
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,443 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// explorer is the state of the detail panel when it navigates in the JSON document of the selected item
+type explorer struct {
+	key    string // The primary value of the explored item, to keep the state across the refreshes
+	root   interface{}
+	open   map[string]bool // The paths of the expanded objects and arrays
+	lines  []explorerLine
+	search string
+}
+
+// explorerLine is a line of the document, either a value or the closing bracket of an expanded container.
+// The path is kept split in members, since a quoted key may contain a "." or a "[".
+type explorerLine struct {
+	segments  []string
+	path      string
+	parent    string
+	container bool
+	closing   bool
+	text      string
+}
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// fieldPath returns the path of the field of an object, in the jq syntax
+func fieldPath(parent, key string) string {
+	if identifierRegexp.MatchString(key) {
+		return parent + "." + key
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+// displayPath returns the path as it is copied, "." designating the whole document
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// explorerDocument returns the document of an item, either structured or parsed from its JSON detail
+func explorerDocument(item MonitoredItem) (interface{}, error) {
+	if ri, ok := item.(*removedItem); ok {
+		item = ri.MonitoredItem
+	}
+	if sd, ok := item.(StructuredDetail); ok {
+		return normalizeDetail(sd.GetStructuredDetail())
+	}
+	decoder := json.NewDecoder(strings.NewReader(item.GetDetail()))
+	decoder.UseNumber()
+	doc, err := decodeDetail(decoder)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return doc, nil
+		}
+	}
+	return nil, errors.New("the detail is not a JSON document")
+}
+
+// countOf returns a count followed by a noun, in the plural if necessary
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func newExplorer(key string, root interface{}) *explorer {
+	ex := &explorer{key: key, root: root, open: map[string]bool{"": true}}
+	// The containers at the first level are expanded too
+	if obj, ok := root.(detailObject); ok {
+		for _, f := range obj {
+			ex.open[fieldPath("", f.key)] = true
+		}
+	}
+	ex.build()
+	return ex
+}
+
+// build lists the visible lines of the document
+func (ex *explorer) build() {
+	ex.lines = ex.lines[:0]
+	ex.add(ex.root, nil, 0, "", true)
+}
+
+// memberPath appends a member to the segments of a path
+func memberPath(segments []string, member string) []string {
+	return append(append(make([]string, 0, len(segments)+1), segments...), member)
+}
+
+func (ex *explorer) add(v interface{}, segments []string, depth int, label string, last bool) {
+	indent := strings.Repeat(" ", depth)
+	comma := ","
+	if last {
+		comma = ""
+	}
+	path := strings.Join(segments, "")
+	parent := ""
+	if len(segments) > 0 {
+		parent = strings.Join(segments[:len(segments)-1], "")
+	}
+	line := explorerLine{segments: segments, path: path, parent: parent, container: !isScalar(v)}
+	switch tv := v.(type) {
+	case detailObject:
+		if len(tv) == 0 || !ex.open[path] {
+			line.text = fmt.Sprintf("%s%s%s{…%s}%s%s", indent, label, ansiBlue, countOf(len(tv), "key"), ansiReset, comma)
+			ex.lines = append(ex.lines, line)
+			return
+		}
+		line.text = indent + label + "{"
+		ex.lines = append(ex.lines, line)
+		for i, f := range tv {
+			label := fmt.Sprintf("%s%q%s: ", styleKey, f.key, ansiReset)
+			ex.add(f.value, memberPath(segments, fieldPath("", f.key)), depth+1, label, i == len(tv)-1)
+		}
+		ex.lines = append(ex.lines, explorerLine{segments: segments, path: path, parent: parent, container: true,
+			closing: true, text: indent + "}" + comma})
+	case []interface{}:
+		if len(tv) == 0 || !ex.open[path] {
+			line.text = fmt.Sprintf("%s%s%s[…%s]%s%s", indent, label, ansiBlue, countOf(len(tv), "item"), ansiReset, comma)
+			ex.lines = append(ex.lines, line)
+			return
+		}
+		line.text = indent + label + "["
+		ex.lines = append(ex.lines, line)
+		for i, elt := range tv {
+			ex.add(elt, memberPath(segments, fmt.Sprintf("[%d]", i)), depth+1, "", i == len(tv)-1)
+		}
+		ex.lines = append(ex.lines, explorerLine{segments: segments, path: path, parent: parent, container: true,
+			closing: true, text: indent + "]" + comma})
+	default:
+		line.text = indent + label + colorScalar(tv, false) + comma
+		ex.lines = append(ex.lines, line)
+	}
+}
+
+// indexOf returns the line of the opening of a path, -1 if it is not visible
+func (ex *explorer) indexOf(path string) int {
+	for i, line := range ex.lines {
+		if line.path == path && !line.closing {
+			return i
+		}
+	}
+	return -1
+}
+
+// explorerMatch is a path of the document with the paths of its ancestors, and if its key or its value
+// contains the searched text
+type explorerMatch struct {
+	path      string
+	ancestors []string
+	matched   bool
+}
+
+// walk lists all the paths of the document, in order
+func (ex *explorer) walk(v interface{}, path, key string, ancestors []string, out []explorerMatch) []explorerMatch {
+	needle := strings.ToLower(ex.search)
+	matched := strings.Contains(strings.ToLower(key), needle)
+	if isScalar(v) && !matched {
+		matched = strings.Contains(strings.ToLower(fmt.Sprint(v)), needle)
+	}
+	out = append(out, explorerMatch{path: path, ancestors: ancestors, matched: matched})
+	sub := append(append([]string{}, ancestors...), path)
+	switch tv := v.(type) {
+	case detailObject:
+		for _, f := range tv {
+			out = ex.walk(f.value, fieldPath(path, f.key), f.key, sub, out)
+		}
+	case []interface{}:
+		for i, elt := range tv {
+			out = ex.walk(elt, fmt.Sprintf("%s[%d]", path, i), "", sub, out)
+		}
+	}
+	return out
+}
+
+// find returns the path of the next match after the given path, in the given direction, expanding its ancestors
+func (ex *explorer) find(from string, step int) (string, bool) {
+	if ex.search == "" {
+		return "", false
+	}
+	all := ex.walk(ex.root, "", "", nil, nil)
+	start := 0
+	for i, m := range all {
+		if m.path == from {
+			start = i
+			break
+		}
+	}
+	for n := 1; n <= len(all); n++ {
+		m := all[((start+n*step)%len(all)+len(all))%len(all)]
+		if m.matched {
+			for _, p := range m.ancestors {
+				ex.open[p] = true
+			}
+			ex.build()
+			return m.path, true
+		}
+	}
+	return "", false
+}
+
+// redrawExplorer renders the document of the selected item, keeping the state when the item is the same
+func (app *monitorApp) redrawExplorer() {
+	current := app.selectedItem()
+	app.panelDetail.Clear()
+	if current == nil {
+		app.explorer = nil
+		app.panelDetail.Title = "Detail (explore)"
+		return
+	}
+
+	var segments []string
+	key := primaryValue(current)
+	doc, err := explorerDocument(current)
+	if err != nil {
+		app.explorer = nil
+		app.panelDetail.Title = fmt.Sprintf("Detail (%v)", err)
+		fmt.Fprintf(app.panelDetail, "%v", current.GetDetail())
+		return
+	}
+	if app.explorer != nil && app.explorer.key == key {
+		segments = app.explorerSegments()
+		app.explorer.root = doc
+		app.explorer.build()
+	} else {
+		app.explorer = newExplorer(key, doc)
+	}
+	app.renderExplorer(segments)
+}
+
+// renderExplorer writes the visible lines and moves the cursor on the given path, or on its closest ancestor
+func (app *monitorApp) renderExplorer(segments []string) {
+	ex := app.explorer
+	app.panelDetail.Clear()
+	for _, line := range ex.lines {
+		fmt.Fprintln(app.panelDetail, line.text)
+	}
+	index := -1
+	for n := len(segments); n >= 0 && index < 0; n-- {
+		index = ex.indexOf(strings.Join(segments[:n], ""))
+	}
+	app.explorerGoto(index)
+}
+
+func (app *monitorApp) explorerIndex() int {
+	_, cy := app.panelDetail.Cursor()
+	_, oy := app.panelDetail.Origin()
+	return cy + oy
+}
+
+// explorerPath returns the path under the cursor of the detail panel
+func (app *monitorApp) explorerPath() string {
+	return strings.Join(app.explorerSegments(), "")
+}
+
+// explorerSegments returns the members of the path under the cursor of the detail panel
+func (app *monitorApp) explorerSegments() []string {
+	index := app.explorerIndex()
+	if app.explorer == nil || index >= len(app.explorer.lines) {
+		return nil
+	}
+	return app.explorer.lines[index].segments
+}
+
+func (app *monitorApp) explorerGoto(index int) {
+	if app.explorer == nil {
+		return
+	}
+	if index >= len(app.explorer.lines) {
+		index = len(app.explorer.lines) - 1
+	}
+	showLine(app.panelDetail, index)
+	app.panelDetail.Title = fmt.Sprintf("Detail (explore) %s", displayPath(app.explorerPath()))
+	if app.explorer.search != "" {
+		app.panelDetail.Title += " /" + app.explorer.search
+	}
+}
+
+// explorerToggle expands or collapses the container under the cursor. An already collapsed node moves the
+// cursor to its parent, and an already expanded one moves it to its first child.
+func (app *monitorApp) explorerToggle(open bool) {
+	ex := app.explorer
+	index := app.explorerIndex()
+	if ex == nil || index >= len(ex.lines) {
+		return
+	}
+	line := ex.lines[index]
+	switch {
+	case line.container && ex.open[line.path] != open:
+		if open {
+			ex.open[line.path] = true
+		} else {
+			delete(ex.open, line.path)
+		}
+		ex.build()
+		app.renderExplorer(line.segments)
+	case open:
+		app.explorerGoto(index + 1)
+	case line.path != "":
+		app.explorerGoto(ex.indexOf(line.parent))
+	}
+}
+
+func (app *monitorApp) explorerSwitch() {
+	if app.explorer != nil {
+		app.explorerToggle(!app.explorer.open[app.explorerPath()])
+	}
+}
+
+// explorerSibling moves the cursor to the next or the previous member of the same container
+func (app *monitorApp) explorerSibling(step int) {
+	ex := app.explorer
+	index := app.explorerIndex()
+	if ex == nil || index >= len(ex.lines) {
+		return
+	}
+	current := ex.lines[index]
+	for i := index + step; i >= 0 && i < len(ex.lines); i += step {
+		line := ex.lines[i]
+		if line.path == current.parent {
+			return
+		}
+		if line.parent == current.parent && !line.closing && line.path != current.path {
+			app.explorerGoto(i)
+			return
+		}
+	}
+}
+
+// explorerFind moves the cursor to the next or the previous match of the search
+func (app *monitorApp) explorerFind(step int) {
+	if app.explorer == nil {
+		return
+	}
+	if path, ok := app.explorer.find(app.explorerPath(), step); ok {
+		// The match is visible once its ancestors are expanded
+		var segments []string
+		if index := app.explorer.indexOf(path); index >= 0 {
+			segments = app.explorer.lines[index].segments
+		}
+		app.renderExplorer(segments)
+	}
+}
+
+func (app *monitorApp) promptExplorerSearch() {
+	if app.explorer == nil {
+		return
+	}
+	app.openPrompt("Search in the detail", app.explorer.search, func(text string) {
+		app.choosePanel(app.panelDetail)
+		if app.explorer != nil {
+			app.explorer.search = text
+			app.explorerFind(1)
+		}
+	})
+}
+
+// copyExplorerPath copies the path under the cursor in the clipboard
+func (app *monitorApp) copyExplorerPath() {
+	if app.explorer == nil {
+		return
+	}
+	path := displayPath(app.explorerPath())
+	if err := copyToClipboard(path); err != nil {
+		app.err = err
+		return
+	}
+	app.panelDetail.Title = fmt.Sprintf("Detail (explore) %s copied", path)
+}
+
+// toggleExplorer enters or leaves the navigation in the detail panel
+func (app *monitorApp) toggleExplorer() {
+	if app.mode == modeExplore {
+		app.leaveExplorer()
+		return
+	}
+	app.explorer = nil
+	app.setMode(modeExplore)
+	if app.explorer != nil {
+		app.choosePanel(app.panelDetail)
+	}
+}
+
+func (app *monitorApp) leaveExplorer() {
+	app.explorer = nil
+	app.setMode(modeDetail)
+	app.choosePanel(app.panelList)
+}
+
+func (app *monitorApp) bindExplorerKeys() {
+	bindings := []struct {
+		key     interface{}
+		handler func()
+	}{
+		{gocui.KeyArrowUp, func() { app.explorerGoto(app.explorerIndex() - 1) }},
+		{gocui.KeyArrowDown, func() { app.explorerGoto(app.explorerIndex() + 1) }},
+		{gocui.KeyArrowRight, func() { app.explorerToggle(true) }},
+		{gocui.KeyArrowLeft, func() { app.explorerToggle(false) }},
+		{gocui.KeyEnter, app.explorerSwitch},
+		{gocui.KeySpace, app.explorerSwitch},
+		{']', func() { app.explorerSibling(1) }},
+		{'[', func() { app.explorerSibling(-1) }},
+		{'/', app.promptExplorerSearch},
+		{'n', func() { app.explorerFind(1) }},
+		{'N', func() { app.explorerFind(-1) }},
+		{'y', app.copyExplorerPath},
+		{gocui.KeyEsc, app.leaveExplorer},
+		{gocui.KeyCtrlG, app.leaveExplorer},
+		{'q', app.leaveExplorer},
+	}
+	for _, b := range bindings {
+		handler := b.handler
+		err := app.gui.SetKeybinding(panelNameDetail, b.key, gocui.ModNone,
+			func(_ *gocui.Gui, _ *gocui.View) error {
+				if app.mode == modeExplore {
+					handler()
+				}
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplorerPaths(t *testing.T) {
+	doc, err := normalizeDetail(map[string]interface{}{
+		"a.b[c]": map[string]interface{}{"x": []interface{}{1, map[string]interface{}{"y": true}}},
+		"plain":  "v",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := newExplorer("k", doc)
+	ex.open[`["a.b[c]"].x`] = true
+	ex.open[`["a.b[c]"].x[1]`] = true
+	ex.build()
+
+	parents := make(map[string]string)
+	for _, line := range ex.lines {
+		if line.path != strings.Join(line.segments, "") {
+			t.Errorf("%q: segments %q", line.path, line.segments)
+		}
+		parents[line.path] = line.parent
+	}
+	expected := map[string]string{
+		"":                  "",
+		`["a.b[c]"]`:        "",
+		`["a.b[c]"].x`:      `["a.b[c]"]`,
+		`["a.b[c]"].x[0]`:   `["a.b[c]"].x`,
+		`["a.b[c]"].x[1]`:   `["a.b[c]"].x`,
+		`["a.b[c]"].x[1].y`: `["a.b[c]"].x[1]`,
+		".plain":            "",
+	}
+	for path, parent := range expected {
+		if got, ok := parents[path]; !ok || got != parent {
+			t.Errorf("%q: parent %q, expected %q", path, got, parent)
+		}
+	}
+	if len(parents) != len(expected) {
+		t.Errorf("unexpected paths %v", parents)
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	cases := map[string]string{"name": ".p.name", "_x1": ".p._x1", "a b": `.p["a b"]`, "1st": `.p["1st"]`,
+		`q"uote`: `.p["q\"uote"]`}
+	for key, want := range cases {
+		if got := fieldPath(".p", key); got != want {
+			t.Errorf("%q: %q instead of %q", key, got, want)
+		}
+	}
+}
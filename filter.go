@@ -0,0 +1,203 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+// filterPatterns returns the comma-separated patterns of the Filter panel
+func (app *monitorApp) filterPatterns() []string {
+	patterns := strings.Split(app.panelFilter.Buffer(), ",")
+	for i, p := range patterns {
+		patterns[i] = strings.Trim(p, " \t\n\r")
+	}
+	return patterns
+}
+
+// keyPattern is a compiled pattern of the Filter panel
+type keyPattern struct {
+	negate bool
+	re     *regexp.Regexp
+}
+
+// keySelector selects and orders the keys displayed as columns of the table
+type keySelector []keyPattern
+
+// parseKeyPatterns compiles the patterns of the Filter panel: fnmatch globs by default, regular expressions with
+// the "re:" prefix, and exclusions with the "!" prefix. The invalid patterns are reported and skipped.
+func parseKeyPatterns(texts []string) (keySelector, error) {
+	out := make(keySelector, 0, len(texts))
+	errs := make([]error, 0)
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		p := keyPattern{}
+		if strings.HasPrefix(text, "!") {
+			p.negate, text = true, text[1:]
+		}
+		var err error
+		if strings.HasPrefix(text, "re:") {
+			p.re, err = regexp.Compile(strings.TrimPrefix(text, "re:"))
+		} else {
+			p.re, err = globRegexp(text)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", text, err))
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, joinErrors("invalid filter patterns", errs)
+}
+
+// rank returns the position of the first positive pattern matching a key, or -1 if the key is not selected.
+// Without any positive pattern, all the keys not excluded are selected.
+func (ks keySelector) rank(k string) int {
+	rank, positives := -1, 0
+	for _, p := range ks {
+		if p.negate {
+			if p.re.MatchString(k) {
+				return -1
+			}
+			continue
+		}
+		if rank < 0 && p.re.MatchString(k) {
+			rank = positives
+		}
+		positives++
+	}
+	if positives == 0 {
+		return 0
+	}
+	return rank
+}
+
+// columns returns the selected keys, in the order of the patterns then in their original order
+func (ks keySelector) columns(keys []string) []string {
+	ranks := make(map[string]int, len(keys))
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if r := ks.rank(k); r >= 0 {
+			ranks[k] = r
+			out = append(out, k)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return ranks[out[i]] < ranks[out[j]] })
+	return out
+}
+
+// keySelector compiles the patterns of the Filter panel, the errors are displayed in the Error panel
+func (app *monitorApp) keySelector() keySelector {
+	selector, err := parseKeyPatterns(app.filterPatterns())
+	app.filterErr = err
+	return selector
+}
+
+// checkFilter parses the patterns of the Filter panel again, after a change of the text
+func (app *monitorApp) checkFilter() {
+	_, app.filterErr = parseKeyPatterns(app.filterPatterns())
+}
+
+// filterEditor edits the Filter panel, its errors being reported as the patterns are typed
+type filterEditor struct {
+	app *monitorApp
+}
+
+func (fe filterEditor) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	gocui.DefaultEditor.Edit(v, key, ch, mod)
+	fe.app.checkFilter()
+}
+
+// exactPattern returns the pattern of the Filter panel matching only the given key
+func exactPattern(k string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "!", `\!`, ",", "?").Replace(k)
+	if strings.HasPrefix(escaped, "re:") {
+		escaped = `\` + escaped
+	}
+	return escaped
+}
+
+// completeFilter completes the pattern before the cursor of the Filter panel with the known keys
+func (app *monitorApp) completeFilter() {
+	text := strings.TrimRight(app.panelFilter.Buffer(), "\r\n")
+	pos := byteOffset(text, app.inputPosition(app.panelFilter))
+	prefix, suffix := text[:pos], text[pos:]
+	head, last := "", strings.TrimLeft(prefix, " ")
+	if i := strings.LastIndex(prefix, ","); i >= 0 {
+		head, last = prefix[:i+1], strings.TrimLeft(prefix[i+1:], " ")
+	}
+
+	candidates := make([]string, 0)
+	for _, k := range app.possibleKeys {
+		if strings.HasPrefix(k, last) {
+			candidates = append(candidates, k)
+		}
+	}
+	apply := func(k string) {
+		setInput(app.panelFilter, head+k+suffix)
+		placeCursor(app.panelFilter, utf8.RuneCountInString(head+k))
+		app.checkFilter()
+	}
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		apply(candidates[0])
+	default:
+		app.openChoice("Keys", app.panelFilter, candidates, apply)
+	}
+}
+
+// pickKeys opens a checklist of the known keys, the ticked keys replace the patterns of the Filter panel
+func (app *monitorApp) pickKeys() {
+	if len(app.possibleKeys) == 0 {
+		return
+	}
+	selector := app.keySelector()
+	keys := append([]string{}, app.possibleKeys...)
+	checked := make([]bool, len(keys))
+	for i, k := range keys {
+		checked[i] = selector.rank(k) >= 0
+	}
+	app.openChecklist("Columns of the table", keys, checked, func(ticked []string) {
+		patterns := make([]string, 0, len(ticked))
+		for _, k := range ticked {
+			patterns = append(patterns, exactPattern(k))
+		}
+		setInput(app.panelFilter, strings.Join(patterns, ","))
+		app.checkFilter()
+		app.remember(app.filters, app.panelFilter)
+		app.redrawTable()
+	})
+}
+
+func (app *monitorApp) bindFilterKeys() {
+	err := app.gui.SetKeybinding(app.panelFilter.Name(), gocui.KeyCtrlSpace, gocui.ModNone,
+		func(_ *gocui.Gui, _ *gocui.View) error {
+			app.completeFilter()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'k', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, _ *gocui.View) error {
+			app.pickKeys()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+}
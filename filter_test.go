@@ -0,0 +1,85 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeySelectorColumns(t *testing.T) {
+	keys := []string{"name", "size", "mode", "mtime", "net.rx", "net.tx"}
+	cases := []struct {
+		patterns string
+		want     string
+		fails    bool
+	}{
+		{patterns: "*", want: "name size mode mtime net.rx net.tx"},
+		{patterns: "", want: "name size mode mtime net.rx net.tx"},
+		{patterns: "size,name", want: "size name"},
+		{patterns: "m*,*", want: "mode mtime name size net.rx net.tx"},
+		{patterns: "!net.*", want: "name size mode mtime"},
+		{patterns: "*,!m*", want: "name size net.rx net.tx"},
+		{patterns: "re:^net\\.(rx|tx)$,name", want: "net.rx net.tx name"},
+		{patterns: "re:(,size", want: "size", fails: true},
+		{patterns: "[x,name", want: "name", fails: true},
+	}
+	for _, c := range cases {
+		texts := strings.Split(c.patterns, ",")
+		for i := range texts {
+			texts[i] = strings.TrimSpace(texts[i])
+		}
+		selector, err := parseKeyPatterns(texts)
+		if (err != nil) != c.fails {
+			t.Errorf("%q: unexpected error %v", c.patterns, err)
+		}
+		if got := strings.Join(selector.columns(keys), " "); got != c.want {
+			t.Errorf("%q: columns %q instead of %q", c.patterns, got, c.want)
+		}
+	}
+}
+
+func TestExactPattern(t *testing.T) {
+	keys := []string{"size", "a*b", "q?", "[x]", "!neg", `back\slash`, "re:prefix"}
+	for _, k := range keys {
+		selector, err := parseKeyPatterns([]string{exactPattern(k)})
+		if err != nil {
+			t.Errorf("%q: %v", k, err)
+			continue
+		}
+		if got := selector.columns(append([]string{"other", "a"}, keys...)); len(got) != 1 || got[0] != k {
+			t.Errorf("%q: selects %q", k, got)
+		}
+	}
+
+	// A comma separates the patterns, it is matched by any character
+	if got := exactPattern("a,b"); got != "a?b" {
+		t.Errorf("a,b: pattern %q", got)
+	}
+}
+
+func TestFitErrorLines(t *testing.T) {
+	partial := joinErrors("failures", []error{errors.New("e1"), errors.New("e2")})
+	cases := []struct {
+		height int
+		errs   []error
+		want   []string
+	}{
+		{height: 4, errs: []error{nil, nil}, want: []string{}},
+		{height: 4, errs: []error{partial, nil}, want: []string{"2 failures", "e1", "e2"}},
+		{height: 4, errs: []error{partial, errors.New("bad filter")}, want: []string{"2 failures", "e1", "e2", "bad filter"}},
+		{height: 3, errs: []error{partial, errors.New("bad filter")}, want: []string{"2 failures", "e1 | e2", "bad filter"}},
+		{height: 1, errs: []error{partial, errors.New("bad filter")}, want: []string{"2 failures | e1 | e2 | bad filter"}},
+		{height: 2, errs: []error{errors.New("x"), partial}, want: []string{"x", "2 failures | e1 | e2"}},
+	}
+	for _, c := range cases {
+		got := fitErrorLines(c.height, c.errs...)
+		if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+			t.Errorf("%d lines: %q instead of %q", c.height, got, c.want)
+		}
+	}
+}
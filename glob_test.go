@@ -0,0 +1,52 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import "testing"
+
+func TestGlobRegexp(t *testing.T) {
+	cases := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+		fails   bool
+	}{
+		{glob: "*", match: []string{"", "a", "a/b"}},
+		{glob: "size", match: []string{"size"}, noMatch: []string{"sizes", "xsize"}},
+		{glob: "s?ze", match: []string{"size", "saze"}, noMatch: []string{"sze"}},
+		{glob: "net.*", match: []string{"net.rx"}, noMatch: []string{"netxrx"}},
+		{glob: "[ab]*", match: []string{"a1", "b"}, noMatch: []string{"c"}},
+		{glob: "[!ab]*", match: []string{"c"}, noMatch: []string{"a"}},
+		{glob: "[]]", match: []string{"]"}},
+		{glob: `a\*`, match: []string{"a*"}, noMatch: []string{"ab"}},
+		{glob: "(x)+", match: []string{"(x)+"}, noMatch: []string{"xx"}},
+		{glob: "[ab", fails: true},
+		{glob: `a\`, fails: true},
+	}
+	for _, c := range cases {
+		re, err := globRegexp(c.glob)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error", c.glob)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", c.glob, err)
+			continue
+		}
+		for _, s := range c.match {
+			if !re.MatchString(s) {
+				t.Errorf("%q should match %q", c.glob, s)
+			}
+		}
+		for _, s := range c.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("%q should not match %q", c.glob, s)
+			}
+		}
+	}
+}
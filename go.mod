@@ -2,9 +2,9 @@ module github.com/jfsmig/cui
 
 go 1.18
 
-require github.com/jroimartin/gocui v0.5.0
-
 require (
-	github.com/mattn/go-runewidth v0.0.9 // indirect
-	github.com/nsf/termbox-go v1.1.1 // indirect
+	github.com/jroimartin/gocui v0.5.0
+	github.com/nsf/termbox-go v1.1.1
 )
+
+require github.com/mattn/go-runewidth v0.0.9 // indirect
@@ -0,0 +1,271 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// aggregate is a function computed over the values of a key in a group of items
+type aggregate struct {
+	fn  string
+	key string
+}
+
+// groupSpec describes the group-by view: the grouping key and the aggregates computed for each group.
+// Its textual form is like "mode count sum:size max:size distinct:owner".
+type groupSpec struct {
+	key        string
+	aggregates []aggregate
+}
+
+// itemGroup gathers the items sharing the same value of the grouping key
+type itemGroup struct {
+	value string
+	items []MonitoredItem
+}
+
+// rowFilter restricts the list to the items of a group
+type rowFilter struct {
+	key   string
+	value string
+}
+
+var aggregateFuncs = map[string]bool{"count": true, "sum": true, "min": true, "max": true, "avg": true,
+	"distinct": true}
+
+func (f *rowFilter) accept(item MonitoredItem) bool { return item.GetValue(f.key) == f.value }
+
+func (f *rowFilter) String() string { return f.key + "=" + f.value }
+
+func parseGroupSpec(s string) (groupSpec, error) {
+	var spec groupSpec
+	tokens := strings.Fields(s)
+	if len(tokens) == 0 {
+		return spec, errors.New("no grouping key")
+	}
+	spec.key = tokens[0]
+	for _, tok := range tokens[1:] {
+		fn, key, _ := strings.Cut(tok, ":")
+		if !aggregateFuncs[fn] {
+			return spec, fmt.Errorf("unknown aggregate %q", fn)
+		}
+		if fn != "count" && key == "" {
+			return spec, fmt.Errorf("aggregate %q needs a key", fn)
+		}
+		spec.aggregates = append(spec.aggregates, aggregate{fn: fn, key: key})
+	}
+	if len(spec.aggregates) == 0 {
+		spec.aggregates = []aggregate{{fn: "count"}}
+	}
+	return spec, nil
+}
+
+func (spec *groupSpec) String() string {
+	tokens := []string{spec.key}
+	for _, agg := range spec.aggregates {
+		tokens = append(tokens, agg.String())
+	}
+	return strings.Join(tokens, " ")
+}
+
+func (agg aggregate) String() string {
+	if agg.key == "" {
+		return agg.fn
+	}
+	return agg.fn + ":" + agg.key
+}
+
+// compute evaluates the aggregate on a group of items. The numeric aggregates ignore the non-numeric values.
+func (agg aggregate) compute(items []MonitoredItem) string {
+	switch agg.fn {
+	case "count":
+		if agg.key == "" {
+			return strconv.Itoa(len(items))
+		}
+		count := 0
+		for _, item := range items {
+			if item.GetValue(agg.key) != "" {
+				count++
+			}
+		}
+		return strconv.Itoa(count)
+	case "distinct":
+		distinct := make(map[string]bool)
+		for _, item := range items {
+			distinct[item.GetValue(agg.key)] = true
+		}
+		return strconv.Itoa(len(distinct))
+	}
+
+	count, sum, min, max := 0, 0.0, math.Inf(1), math.Inf(-1)
+	for _, item := range items {
+		v, err := strconv.ParseFloat(strings.TrimSpace(item.GetValue(agg.key)), 64)
+		if err != nil {
+			continue
+		}
+		count++
+		sum += v
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if count == 0 {
+		return "-"
+	}
+	switch agg.fn {
+	case "sum":
+		return formatFloat(sum)
+	case "min":
+		return formatFloat(min)
+	case "max":
+		return formatFloat(max)
+	default:
+		return formatFloat(sum / float64(count))
+	}
+}
+
+// promptGroupBy offers the known keys to group the items by, then asks for the aggregates
+func (app *monitorApp) promptGroupBy() {
+	if len(app.possibleKeys) == 0 {
+		app.err = errors.New("no key to group by")
+		return
+	}
+	keys := append([]string{}, app.possibleKeys...)
+	current := app.groupSpec.key
+	if current == "" {
+		current = app.itemKeyNameOrPrimary()
+	}
+	app.openChoice("Group by", nil, keys, app.promptAggregates)
+	for i, k := range keys {
+		if k == current {
+			showLine(app.popup.view, i)
+		}
+	}
+}
+
+// promptAggregates asks for the aggregates computed for each group of the given key, then displays the groups
+func (app *monitorApp) promptAggregates(key string) {
+	initial := "count"
+	if len(app.groupSpec.aggregates) > 0 {
+		initial = strings.TrimPrefix(app.groupSpec.String(), app.groupSpec.key+" ")
+	}
+	app.openPrompt("Group by "+key+": [count|sum|min|max|avg|distinct:key ...]", initial, func(text string) {
+		spec, err := parseGroupSpec(key + " " + text)
+		if err == nil {
+			err = app.checkGroupKeys(spec)
+		}
+		if err != nil {
+			app.err = err
+			return
+		}
+		app.groupSpec = spec
+		app.rowFilter = nil
+		app.setMode(modeGroup)
+		app.choosePanel(app.panelList)
+	})
+}
+
+// checkGroupKeys rejects a group-by specification on keys that no item has
+func (app *monitorApp) checkGroupKeys(spec groupSpec) error {
+	if !app.knownKeys[spec.key] {
+		return fmt.Errorf("group by: unknown key %q", spec.key)
+	}
+	for _, agg := range spec.aggregates {
+		if agg.key != "" && !app.knownKeys[agg.key] {
+			return fmt.Errorf("group by: unknown key %q in %s", agg.key, agg)
+		}
+	}
+	return nil
+}
+
+// itemKeyNameOrPrimary returns the current key or else the primary key of the first item
+func (app *monitorApp) itemKeyNameOrPrimary() string {
+	if app.currentKey != "" || len(app.items) == 0 {
+		return app.currentKey
+	}
+	return app.items[0].GetPrimaryKey()
+}
+
+// computeGroups gathers the items by value of the grouping key
+func (app *monitorApp) computeGroups() {
+	index := make(map[string]int)
+	app.groups = app.groups[:0]
+	for _, item := range app.items {
+		if _, ok := item.(*removedItem); ok {
+			continue
+		}
+		value := item.GetValue(app.groupSpec.key)
+		i, ok := index[value]
+		if !ok {
+			i = len(app.groups)
+			index[value] = i
+			app.groups = append(app.groups, itemGroup{value: value})
+		}
+		app.groups[i].items = append(app.groups[i].items, item)
+	}
+	sort.Slice(app.groups, func(i, j int) bool { return app.groups[i].value < app.groups[j].value })
+}
+
+// renderGroups writes the groups in the list panel
+func (app *monitorApp) renderGroups() {
+	app.computeGroups()
+	app.panelList.Clear()
+	separator := ""
+	for _, g := range app.groups {
+		value := g.value
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Fprintf(app.panelList, "%s%s", separator, value)
+		separator = "\n"
+	}
+	app.panelList.Title = fmt.Sprintf("%s (%d groups)", app.groupSpec.key, len(app.groups))
+}
+
+// redrawGroupTable writes the aggregates of each group in the detail panel, aligned with the list
+func (app *monitorApp) redrawGroupTable() {
+	app.panelDetail.Clear()
+	header := make([]string, 0, len(app.groupSpec.aggregates))
+	for _, agg := range app.groupSpec.aggregates {
+		header = append(header, agg.String())
+	}
+	app.panelDetail.Title = strings.Join(header, " | ")
+
+	rows := make([][]tableCell, 0, len(app.groups))
+	for _, g := range app.groups {
+		row := make([]tableCell, 0, len(app.groupSpec.aggregates))
+		for _, agg := range app.groupSpec.aggregates {
+			row = append(row, tableCell{text: agg.compute(g.items)})
+		}
+		rows = append(rows, row)
+	}
+	writeTable(app.panelDetail, rows)
+}
+
+// drillIntoGroup restricts the list to the items of the selected group
+func (app *monitorApp) drillIntoGroup() {
+	index := app.selectedIndex()
+	if index < 0 || index >= len(app.groups) {
+		return
+	}
+	app.rowFilter = &rowFilter{key: app.groupSpec.key, value: app.groups[index].value}
+	app.setMode(app.groupReturnMode)
+}
+
+// leaveGroup drops the restriction to a group and goes back to the groups
+func (app *monitorApp) leaveGroup() bool {
+	if app.rowFilter == nil {
+		return false
+	}
+	app.rowFilter = nil
+	app.setMode(modeGroup)
+	return true
+}
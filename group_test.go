@@ -0,0 +1,87 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import "testing"
+
+func TestParseGroupSpec(t *testing.T) {
+	cases := []struct {
+		spec  string
+		want  string
+		fails bool
+	}{
+		{spec: "mode", want: "mode count"},
+		{spec: "  mode   count  sum:size ", want: "mode count sum:size"},
+		{spec: "owner max:size distinct:mode count:target", want: "owner max:size distinct:mode count:target"},
+		{spec: "", fails: true},
+		{spec: "mode median:size", fails: true},
+		{spec: "mode sum", fails: true},
+	}
+	for _, c := range cases {
+		spec, err := parseGroupSpec(c.spec)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", c.spec, spec.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.spec, err)
+		} else if got := spec.String(); got != c.want {
+			t.Errorf("%q: got %q, expected %q", c.spec, got, c.want)
+		}
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	items := []MonitoredItem{
+		testItem("a", "size", "10", "mode", "r"),
+		testItem("b", "size", "2.5", "mode", "w"),
+		testItem("c", "size", "n/a", "mode", "r"),
+		testItem("d", "mode", "r"),
+	}
+	cases := map[string]string{
+		"count":         "4",
+		"count:size":    "3",
+		"distinct:mode": "2",
+		"sum:size":      "12.5",
+		"min:size":      "2.5",
+		"max:size":      "10",
+		"avg:size":      "6.25",
+		"sum:missing":   "-",
+	}
+	for text, want := range cases {
+		spec, err := parseGroupSpec("mode " + text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := spec.aggregates[0].compute(items); got != want {
+			t.Errorf("%s: got %s, expected %s", text, got, want)
+		}
+	}
+}
+
+func TestCheckGroupKeys(t *testing.T) {
+	app := newTestApp(testItem("a", "mode", "0644", "size", "1"))
+	cases := []struct {
+		spec  string
+		fails bool
+	}{
+		{spec: "mode count"},
+		{spec: "mode sum:size distinct:id"},
+		{spec: "mdoe count", fails: true},
+		{spec: "mode max:szie", fails: true},
+	}
+	for _, c := range cases {
+		spec, err := parseGroupSpec(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = app.checkGroupKeys(spec); (err != nil) != c.fails {
+			t.Errorf("%q: unexpected error %v", c.spec, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// highlightDelay is how long a row stays highlighted after a change
+const highlightDelay = 2 * time.Second
+
+// ANSI sequences understood by gocui in gocui.OutputNormal
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiUnder   = "\x1b[4m"
+	ansiReverse = "\x1b[7m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// rowMark is a temporary style applied to the row of an item in the list
+type rowMark struct {
+	style string
+	until time.Time
+}
+
+// primaryValue identifies an item among the items of a source
+func primaryValue(item MonitoredItem) string { return item.GetValue(item.GetPrimaryKey()) }
+
+// markRow highlights the row of the item identified by the given primary key value, for a short while
+func (app *monitorApp) markRow(key, style string) {
+	if app.marks == nil {
+		app.marks = make(map[string]rowMark)
+	}
+	app.marks[key] = rowMark{style: style, until: time.Now().Add(highlightDelay)}
+	if !app.marksTimer {
+		app.marksTimer = true
+		time.AfterFunc(highlightDelay, func() {
+			app.gui.Update(func(_ *gocui.Gui) error {
+				app.marksTimer = false
+				app.expireMarks()
+				return nil
+			})
+		})
+	}
+}
+
+// rowStyle returns the ANSI style of the row of an item, or an empty string.
+// An alert prevails over a recent event, that prevails over the changes since the previous fetch.
+func (app *monitorApp) rowStyle(item MonitoredItem) string {
+	style := app.changeStyle(item)
+	if mark, ok := app.marks[primaryValue(item)]; ok && time.Now().Before(mark.until) {
+		style = mark.style
+	}
+	if alert := app.alertStyle(item); alert != "" {
+		style = alert
+	}
+	if app.base != nil && primaryValue(app.base) == primaryValue(item) {
+		// The base of the comparisons is underlined, whatever its color
+		style = ansiUnder + style
+	}
+	return style
+}
+
+// expireMarks drops the outdated marks and redraws the list if any was dropped
+func (app *monitorApp) expireMarks() {
+	now := time.Now()
+	expired, dropped := false, false
+	selected := app.selectedItem()
+	var next time.Time
+	for k, mark := range app.marks {
+		if now.Before(mark.until) {
+			if next.IsZero() || mark.until.Before(next) {
+				next = mark.until
+			}
+		} else {
+			delete(app.marks, k)
+			expired = true
+			dropped = app.dropWatchGhost(k) || dropped
+		}
+	}
+	if dropped {
+		app.refreshList(selected)
+	} else if expired {
+		app.renderList()
+	}
+	if !next.IsZero() && !app.marksTimer {
+		app.marksTimer = true
+		time.AfterFunc(next.Sub(now), func() {
+			app.gui.Update(func(_ *gocui.Gui) error {
+				app.marksTimer = false
+				app.expireMarks()
+				return nil
+			})
+		})
+	}
+}
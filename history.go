@@ -0,0 +1,184 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// historyDepth is the number of samples kept for each value
+	historyDepth = 60
+
+	// sparklineWidth is the number of samples rendered in a cell of the table
+	sparklineWidth = 8
+
+	// chartHeight is the number of lines of the chart in the detail panel
+	chartHeight = 5
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sample is a numeric value observed at a given fetch
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// valueRing keeps the last samples of a value, the oldest first
+type valueRing struct {
+	samples []sample
+	next    int
+}
+
+func (r *valueRing) push(s sample) {
+	if len(r.samples) < historyDepth {
+		r.samples = append(r.samples, s)
+		return
+	}
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % historyDepth
+}
+
+// last returns at most n samples, the oldest first
+func (r *valueRing) last(n int) []sample {
+	out := make([]sample, 0, len(r.samples))
+	out = append(out, r.samples[r.next:]...)
+	out = append(out, r.samples[:r.next]...)
+	if len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+// itemHistory holds the rings of the numeric values of an item, by key
+type itemHistory map[string]*valueRing
+
+// recordItem appends the numeric values of the item to its history
+func (app *monitorApp) recordItem(item MonitoredItem, at time.Time, into map[string]itemHistory) {
+	if _, ok := item.(*removedItem); ok || into == nil {
+		return
+	}
+	pk := primaryValue(item)
+	h, ok := app.history[pk]
+	if !ok {
+		h = make(itemHistory)
+	}
+	for _, k := range item.GetKeys() {
+		v, err := strconv.ParseFloat(strings.TrimSpace(item.GetValue(k)), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		ring, ok := h[k]
+		if !ok {
+			ring = &valueRing{}
+			h[k] = ring
+		}
+		ring.push(sample{at: at, value: v})
+	}
+	into[pk] = h
+}
+
+// recordHistory appends a sample of all the numeric values of all the items, and forgets the items that vanished.
+func (app *monitorApp) recordHistory() {
+	now := time.Now()
+	history := make(map[string]itemHistory, len(app.items))
+	for _, item := range app.items {
+		app.recordItem(item, now, history)
+	}
+	app.history = history
+	app.lastFetch = now
+}
+
+// samplesOf returns the last samples of a value of an item
+func (app *monitorApp) samplesOf(item MonitoredItem, k string, n int) []sample {
+	if _, ok := item.(*removedItem); ok {
+		return nil
+	}
+	h, ok := app.history[primaryValue(item)]
+	if !ok {
+		return nil
+	}
+	ring, ok := h[k]
+	if !ok {
+		return nil
+	}
+	return ring.last(n)
+}
+
+func sampleBounds(samples []sample) (min, max, avg float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, s := range samples {
+		min = math.Min(min, s.value)
+		max = math.Max(max, s.value)
+		avg += s.value
+	}
+	return min, max, avg / float64(len(samples))
+}
+
+// sparkline renders the samples on a single line, or an empty string when there are too few of them
+func sparkline(samples []sample) string {
+	if len(samples) < 2 {
+		return ""
+	}
+	min, max, _ := sampleBounds(samples)
+	var b strings.Builder
+	for _, s := range samples {
+		level := 0
+		if max > min {
+			level = int((s.value - min) / (max - min) * float64(len(sparkRunes)-1))
+		}
+		b.WriteRune(sparkRunes[level])
+	}
+	return b.String()
+}
+
+// writeChart renders the history of a value as a bar chart of a few lines, with its min/max/avg
+func writeChart(w io.Writer, k string, samples []sample) {
+	if len(samples) < 2 {
+		return
+	}
+	min, max, avg := sampleBounds(samples)
+	fmt.Fprintf(w, "\n%s%s%s: min=%s max=%s avg=%s (%d samples)\n", ansiBold, k, ansiReset,
+		formatFloat(min), formatFloat(max), formatFloat(avg), len(samples))
+
+	// Each line holds the eighths of a block, the top line first
+	levels := make([]int, len(samples))
+	for i, s := range samples {
+		levels[i] = chartHeight * len(sparkRunes)
+		if max > min {
+			levels[i] = 1 + int((s.value-min)/(max-min)*float64(chartHeight*len(sparkRunes)-1))
+		}
+	}
+	for line := chartHeight - 1; line >= 0; line-- {
+		var b strings.Builder
+		for _, level := range levels {
+			eighths := level - line*len(sparkRunes)
+			switch {
+			case eighths <= 0:
+				b.WriteRune(' ')
+			case eighths >= len(sparkRunes):
+				b.WriteRune(sparkRunes[len(sparkRunes)-1])
+			default:
+				b.WriteRune(sparkRunes[eighths-1])
+			}
+		}
+		fmt.Fprintf(w, "%s%s%s\n", ansiCyan, b.String(), ansiReset)
+	}
+}
+
+// formatFloat renders the integers in full, and the other values with 6 significant digits
+func formatFloat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
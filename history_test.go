@@ -0,0 +1,111 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"strings"
+	"testing"
+)
+
+func samplesOf(values ...float64) []sample {
+	out := make([]sample, 0, len(values))
+	for _, v := range values {
+		out = append(out, sample{value: v})
+	}
+	return out
+}
+
+func sampleValues(samples []sample) []float64 {
+	out := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		out = append(out, s.value)
+	}
+	return out
+}
+
+func TestValueRing(t *testing.T) {
+	cases := []struct {
+		pushed int
+		n      int
+		first  float64 // The oldest of the returned samples
+		count  int
+	}{
+		{pushed: 0, n: 5, count: 0},
+		{pushed: 3, n: 5, first: 0, count: 3},
+		{pushed: 3, n: 2, first: 1, count: 2},
+		{pushed: historyDepth, n: historyDepth, first: 0, count: historyDepth},
+		{pushed: historyDepth + 7, n: historyDepth, first: 7, count: historyDepth},
+		{pushed: 3*historyDepth + 1, n: 4, first: 3*historyDepth - 3, count: 4},
+	}
+	for _, c := range cases {
+		var r valueRing
+		for i := 0; i < c.pushed; i++ {
+			r.push(sample{value: float64(i)})
+		}
+		got := sampleValues(r.last(c.n))
+		if len(got) != c.count {
+			t.Errorf("%d pushed, last %d: %d samples", c.pushed, c.n, len(got))
+			continue
+		}
+		for i, v := range got {
+			if v != c.first+float64(i) {
+				t.Errorf("%d pushed, last %d: %v", c.pushed, c.n, got)
+				break
+			}
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	cases := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{3}, ""},
+		{[]float64{1, 1, 1}, "▁▁▁"},
+		{[]float64{0, 7}, "▁█"},
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]float64{-5, 5, 0}, "▁█▄"},
+	}
+	for _, c := range cases {
+		if got := sparkline(samplesOf(c.values...)); got != c.want {
+			t.Errorf("%v: %q instead of %q", c.values, got, c.want)
+		}
+	}
+}
+
+func TestWriteChart(t *testing.T) {
+	cases := []struct {
+		values []float64
+		want   []string
+	}{
+		{values: []float64{1}, want: nil},
+		{values: []float64{0, 1}, want: []string{
+			"k: min=0 max=1 avg=0.5 (2 samples)",
+			" █", " █", " █", " █", "▁█",
+		}},
+		{values: []float64{2, 2}, want: []string{
+			"k: min=2 max=2 avg=2 (2 samples)",
+			"██", "██", "██", "██", "██",
+		}},
+	}
+	clean := strings.NewReplacer(ansiBold, "", ansiCyan, "", ansiReset, "")
+	for _, c := range cases {
+		var b strings.Builder
+		writeChart(&b, "k", samplesOf(c.values...))
+		got := strings.Split(strings.TrimSpace(clean.Replace(b.String())), "\n")
+		if c.want == nil {
+			if b.Len() != 0 {
+				t.Errorf("%v: %q instead of nothing", c.values, b.String())
+			}
+			continue
+		}
+		if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+			t.Errorf("%v: got\n%s", c.values, strings.Join(got, "\n"))
+		}
+	}
+}
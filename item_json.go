@@ -0,0 +1,229 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultPrimaryKeys are the fields tried, in order, as the primary key of a JSON object.
+var defaultPrimaryKeys = []string{"id", "key", "name", "path"}
+
+// jsonItem is a MonitoredItem wrapping a decoded JSON object
+type jsonItem struct {
+	fields  map[string]interface{}
+	keys    []string
+	primary string
+
+	// The nested objects, built on the first descent
+	childrenOnce sync.Once
+	children     []MonitoredItem
+}
+
+func newJSONItem(fields map[string]interface{}, primary string) *jsonItem {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if primary == "" {
+		for _, k := range defaultPrimaryKeys {
+			if _, ok := fields[k]; ok {
+				primary = k
+				break
+			}
+		}
+	}
+	if primary == "" && len(keys) > 0 {
+		primary = keys[0]
+	}
+	return &jsonItem{fields: fields, keys: keys, primary: primary}
+}
+
+func (ji *jsonItem) GetPrimaryKey() string { return ji.primary }
+
+func (ji *jsonItem) GetKeys() []string { return ji.keys }
+
+func (ji *jsonItem) GetValue(k string) string {
+	v, ok := ji.fields[k]
+	if !ok {
+		return ""
+	}
+	return formatJSONValue(v)
+}
+
+func (ji *jsonItem) GetDetail() string {
+	var builder strings.Builder
+	encoder := json.NewEncoder(&builder)
+	encoder.SetIndent("", " ")
+	encoder.Encode(ji.fields)
+	return builder.String()
+}
+
+// GetStructuredDetail exposes the decoded fields, rendered by the detail panel
+func (ji *jsonItem) GetStructuredDetail() interface{} { return ji.fields }
+
+// Children returns the objects nested in the fields of the object, directly or in arrays
+func (ji *jsonItem) Children() []MonitoredItem {
+	ji.childrenOnce.Do(func() {
+		for _, k := range ji.keys {
+			switch tv := ji.fields[k].(type) {
+			case map[string]interface{}:
+				ji.children = append(ji.children, newJSONItem(tv, ""))
+			case []interface{}:
+				for _, elt := range tv {
+					if obj, ok := elt.(map[string]interface{}); ok {
+						ji.children = append(ji.children, newJSONItem(obj, ""))
+					}
+				}
+			}
+		}
+	})
+	return ji.children
+}
+
+// HasChildren tells if an object is nested in the fields, without building the children
+func (ji *jsonItem) HasChildren() bool {
+	for _, v := range ji.fields {
+		switch tv := v.(type) {
+		case map[string]interface{}:
+			return true
+		case []interface{}:
+			for _, elt := range tv {
+				if _, ok := elt.(map[string]interface{}); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// formatJSONValue renders a scalar as plain text and anything else as compact JSON
+func formatJSONValue(v interface{}) string {
+	switch tv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return tv
+	case json.Number:
+		return tv.String()
+	case float64:
+		return strconv.FormatFloat(tv, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(tv)
+	default:
+		b, err := json.Marshal(tv)
+		if err != nil {
+			return fmt.Sprint(tv)
+		}
+		return string(b)
+	}
+}
+
+// jsonItems turns an array of JSON objects into items
+func jsonItems(doc interface{}, primary string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+	array, ok := doc.([]interface{})
+	if !ok {
+		if obj, ok := doc.(map[string]interface{}); ok {
+			return append(out, newJSONItem(obj, primary)), nil
+		}
+		return out, errors.New("not an array of objects")
+	}
+
+	var errs []error
+	for i, elt := range array {
+		obj, ok := elt.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("element %d: not an object", i))
+			continue
+		}
+		out = append(out, newJSONItem(obj, primary))
+	}
+	return out, joinErrors("invalid elements", errs)
+}
+
+// selectJSON walks a decoded JSON document along a simple path like "$.data.items" or ".results[0].objects".
+// The "[*]" step projects the rest of the path over the elements of an array, the arrays selected in the elements
+// being flattened, like ".clusters[*].nodes". An empty path selects the whole document.
+func selectJSON(doc interface{}, path string) (interface{}, error) {
+	return selectJSONFrom(doc, strings.TrimPrefix(strings.TrimSpace(path), "$"), "")
+}
+
+func selectJSONFrom(doc interface{}, path, walked string) (interface{}, error) {
+	current := doc
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			name := path[:end]
+			path = path[end:]
+			if name == "" {
+				continue
+			}
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("selector %q: not an object", walked)
+			}
+			if current, ok = obj[name]; !ok {
+				return nil, fmt.Errorf("selector %q: no field %q", walked, name)
+			}
+			walked += "." + name
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: unterminated index", walked)
+			}
+			index := path[1:end]
+			path = path[end+1:]
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("selector %q: not an array", walked)
+			}
+			if index == "*" {
+				return projectJSON(array, path, walked)
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 || i >= len(array) {
+				return nil, fmt.Errorf("selector %q: invalid index %q", walked, index)
+			}
+			current = array[i]
+			walked += "[" + index + "]"
+		default:
+			return nil, fmt.Errorf("selector %q: unexpected %q", walked, path[0])
+		}
+	}
+	return current, nil
+}
+
+// projectJSON selects the rest of a path in each element of an array, and flattens the selected arrays
+func projectJSON(array []interface{}, path, walked string) (interface{}, error) {
+	out := make([]interface{}, 0, len(array))
+	for i, elt := range array {
+		selected, err := selectJSONFrom(elt, path, fmt.Sprintf("%s[%d]", walked, i))
+		if err != nil {
+			return nil, err
+		}
+		if nested, ok := selected.([]interface{}); ok && path != "" {
+			out = append(out, nested...)
+		} else {
+			out = append(out, selected)
+		}
+	}
+	return out, nil
+}
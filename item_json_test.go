@@ -0,0 +1,86 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSelectJSON(t *testing.T) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{
+		"data": {"items": [{"name": "a", "tags": ["x", "y"]}, {"name": "b", "tags": ["z"]}]},
+		"results": [{"objects": [1, 2]}],
+		"count": 2
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path  string
+		want  string
+		fails bool
+	}{
+		{path: "", want: `{"count":2,"data":{"items":[{"name":"a","tags":["x","y"]},{"name":"b","tags":["z"]}]},"results":[{"objects":[1,2]}]}`},
+		{path: "$.count", want: `2`},
+		{path: ".data.items[1].name", want: `"b"`},
+		{path: ".results[0].objects", want: `[1,2]`},
+		{path: ".data.items[*]", want: `[{"name":"a","tags":["x","y"]},{"name":"b","tags":["z"]}]`},
+		{path: ".data.items[*].name", want: `["a","b"]`},
+		{path: "$.data.items[*].tags", want: `["x","y","z"]`},
+		{path: ".data.items[*].tags[0]", want: `["x","z"]`},
+		{path: ".results[*].objects[*]", want: `[1,2]`},
+		{path: ".data.items[*].missing", fails: true},
+		{path: ".count[*]", fails: true},
+		{path: ".count.x", fails: true},
+		{path: ".data.items[2]", fails: true},
+		{path: ".data.items[", fails: true},
+		{path: "count", fails: true},
+	}
+	for _, c := range cases {
+		got, err := selectJSON(doc, c.path)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", c.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.path, err)
+			continue
+		}
+		if encoded, _ := json.Marshal(got); string(encoded) != c.want {
+			t.Errorf("%q: got %s, expected %s", c.path, encoded, c.want)
+		}
+	}
+}
+
+func TestJSONItemChildren(t *testing.T) {
+	var fields map[string]interface{}
+	err := json.Unmarshal([]byte(`{"id": "h1", "disk": {"id": "d0"}, "nics": [{"id": "n0"}, 3, {"id": "n1"}],
+		"tags": ["a", "b"]}`), &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := newJSONItem(fields, "")
+	if !item.HasChildren() || !isParent(item) {
+		t.Fatal("the item has nested objects")
+	}
+	children := item.Children()
+	if got := listedIDs(children); got != "d0 n0 n1" {
+		t.Fatalf("children %q", got)
+	}
+	if again := item.Children(); &again[0] != &children[0] {
+		t.Error("the children should be built once")
+	}
+
+	leaf := newJSONItem(map[string]interface{}{"id": "x", "tags": []interface{}{"a"}}, "")
+	if leaf.HasChildren() || isParent(leaf) || len(leaf.Children()) != 0 {
+		t.Error("the item has no nested object")
+	}
+}
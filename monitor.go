@@ -8,10 +8,10 @@ package cui
 import (
 	"fmt"
 	"log"
-	"regexp"
+	"path/filepath"
 	"sort"
 	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/jroimartin/gocui"
 )
@@ -34,6 +34,10 @@ const (
 const (
 	modeDetail detailMode = iota
 	modeTable
+	modeGroup
+	modeStats
+	modeExplore
+	modeCompare
 )
 
 // MonitoredItem describe the expectation for any monitorable item: just a set of metadata tha can be queried
@@ -63,6 +67,8 @@ type MonitoredItem interface {
 // two fetches.
 type Monitorable interface {
 	// FetchAll returns the whole list of items. They will be saved in the CLI app. until the next FetchAll call.
+	// The items returned along with an error are displayed too, so that a source may report a partial failure
+	// (e.g. with a PartialError) without dropping what could be fetched.
 	FetchAll(query string) ([]MonitoredItem, error)
 }
 
@@ -90,14 +96,99 @@ type monitorApp struct {
 
 	source Monitorable
 
+	// The persistent histories of the Query and the Filter panels
+	queries *inputHistory
+	filters *inputHistory
+
 	mode  detailMode
 	query string
 	err   error
 
+	// The error in the patterns of the Filter panel, displayed along with the error of the source
+	filterErr error
+
+	// The rendering of the items implementing StructuredDetail, and the navigation in the detail
+	detailFormat detailFormat
+	explorer     *explorer
+	base         MonitoredItem // The item compared to the selected one
+
+	// The scroll of the detail panel is kept as long as the same item is displayed
+	detailKey   string
+	wrapDetail  bool
+	lineNumbers bool
+
+	// All the items of the query, sorted on the current key, and the subset displayed in the list
 	items      []MonitoredItem
+	rows       []MonitoredItem
+	rowFilter  *rowFilter
+	search     string
 	currentKey string
 
+	// The tree mode of the list: the visible nodes, the paths of the open nodes and the children already fetched
+	treeMode  bool
+	treeRows  []*treeNode
+	expanded  map[string]bool
+	treeCache map[string][]MonitoredItem
+
+	// The deadline of the fetches of children for the search being submitted, and whether some were skipped
+	searchUntil   time.Time
+	searchPartial bool
+
 	possibleKeys sort.StringSlice
+	knownKeys    map[string]bool
+
+	// The stream in progress, if the source is a StreamingMonitorable
+	stream        *itemStream
+	streamStopped bool
+
+	// The watch in progress, if the source is a WatchableMonitorable
+	watch *itemWatch
+
+	// Temporary highlights of the rows, indexed by primary key value
+	marks      map[string]rowMark
+	marksTimer bool
+
+	// The items of the previous fetch of the same query, indexed by primary key value, and how they changed
+	previous map[string]MonitoredItem
+	changes  map[string]changeKind
+
+	// The items of a snapshot file replacing the previous fetch in the comparisons
+	baseline     map[string]MonitoredItem
+	baselinePath string
+
+	// The recent numeric values of the items of the query, indexed by primary key value, and the time of the
+	// last fetch
+	history   map[string]itemHistory
+	lastFetch time.Time
+
+	// The keys whose values are counters, and how their rates are displayed in the table
+	counters map[string]bool
+	rateMode rateMode
+
+	// The group-by view, and the mode restored when drilling into a group
+	groupSpec       groupSpec
+	groups          []itemGroup
+	groupReturnMode detailMode
+
+	// The states of the parent levels, when descended into the children of an item
+	levels []level
+
+	// The popup open over the panels, if any
+	popup *popup
+
+	// The presets proposed by the source and the configuration file, and the name of the last one applied
+	presets []Preset
+	preset  string
+
+	// The width of the list panel, and the fixed widths of the columns of the table by key
+	listWidth int
+	widths    map[string]int
+
+	// The alert rules and the items that match them, indexed by primary key value
+	rules    []alertRule
+	alerts   map[string]alertState
+	blinking bool
+	blinkOn  bool
 }
 
 // Monitor displays a terminal application that navigates in the data source
@@ -105,11 +196,34 @@ func Monitor(listable Monitorable, firstQuery string) error {
 	var err error
 
 	app := monitorApp{
-		source: listable,
-		mode:   modeDetail,
-		query:  firstQuery,
+		source:    listable,
+		mode:      modeDetail,
+		query:     firstQuery,
+		listWidth: widthList,
 	}
 
+	if counting, ok := listable.(CounterMonitorable); ok {
+		app.counters = make(map[string]bool)
+		for _, k := range counting.CounterKeys() {
+			app.counters[k] = true
+		}
+	}
+
+	var rulesErr error
+	if alerting, ok := listable.(AlertingMonitorable); ok {
+		rulesErr = app.setAlertRules(alerting.AlertRules())
+	}
+
+	if presenting, ok := listable.(PresetMonitorable); ok {
+		app.presets = mergePresets(app.presets, presenting.Presets()...)
+	}
+	saved, presetsErr := loadPresets()
+	app.presets = mergePresets(app.presets, saved...)
+
+	queries, queriesErr := loadInputHistory("queries")
+	filters, filtersErr := loadInputHistory("filters")
+	app.queries, app.filters = queries, filters
+
 	app.gui, err = gocui.NewGui(gocui.OutputNormal)
 	if err != nil {
 		log.Panicf("GUI open error: %v", err)
@@ -121,6 +235,13 @@ func Monitor(listable Monitorable, firstQuery string) error {
 	app.createPanels()
 	app.bindKeys()
 	app.doQuery()
+	for _, e := range []error{rulesErr, presetsErr, queriesErr, filtersErr} {
+		if e != nil && app.err == nil {
+			app.err = e
+		}
+	}
+	defer app.cancelStream()
+	defer app.cancelWatch()
 	app.choosePanel(app.panelQuery)
 
 	if err := app.gui.MainLoop(); err != nil && err != gocui.ErrQuit {
@@ -165,8 +286,8 @@ func (app *monitorApp) createPanels() {
 			app.panelFilter.SelBgColor = gocui.ColorYellow
 			app.panelFilter.Highlight = true
 			app.panelFilter.Editable = true
-			app.panelFilter.Editor = gocui.DefaultEditor
-			fmt.Fprint(app.panelFilter, ".*")
+			app.panelFilter.Editor = filterEditor{app}
+			fmt.Fprint(app.panelFilter, "*")
 		}
 	} else {
 		log.Panicln("WTF")
@@ -223,38 +344,152 @@ func (app *monitorApp) bindKeys() {
 
 	// General commands, whatever the current panel
 	err = app.gui.SetKeybinding("", gocui.KeyTab, gocui.ModNone,
-		func(_ *gocui.Gui, v *gocui.View) error {
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
 			switch app.gui.CurrentView() {
 			case app.panelQuery:
+				app.remember(app.queries, app.panelQuery)
 				app.doQuery()
 				app.redrawList()
 				app.redrawTable()
 				app.choosePanel(app.panelFilter)
 			case app.panelFilter:
+				app.checkFilter()
+				app.remember(app.filters, app.panelFilter)
 				app.redrawTable()
 				app.choosePanel(app.panelList)
 			case app.panelList:
+				app.choosePanel(app.panelDetail)
+			case app.panelDetail:
 				app.choosePanel(app.panelQuery)
 			}
 			return nil
-		})
+		}))
 	if err != nil {
 		log.Panicln(err)
 	}
 	err = app.gui.SetKeybinding("", 'm', gocui.ModAlt,
-		func(_ *gocui.Gui, v *gocui.View) error {
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
 			switch app.mode {
-			case modeTable:
-				app.mode = modeDetail
-				app.panelDetail.Highlight = false
 			case modeDetail:
-				app.mode = modeTable
-				app.panelDetail.Highlight = true
-				app.redrawTable()
+				app.setMode(modeTable)
+			default:
+				app.setMode(modeDetail)
 			}
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'g', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.promptGroupBy()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 't', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			if app.mode == modeStats {
+				app.setMode(modeDetail)
+			} else {
+				app.setMode(modeStats)
+			}
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'e', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.toggleTree()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'f', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.detailFormat = (app.detailFormat + 1) % (formatKeyValue + 1)
 			app.redrawDetail()
 			return nil
-		})
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'j', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.toggleExplorer()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'b', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.toggleBase()
+			app.renderList()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'w', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.promptSnapshot()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'l', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.promptBaseline()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'n', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.shiftCurrentKey(1)
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'p', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.shiftCurrentKey(-1)
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'c', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.toggleCounter()
+			app.redrawTable()
+			app.redrawDetail()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'r', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.rateMode = (app.rateMode + 1) % (rateHidden + 1)
+			app.redrawTable()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 's', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, v *gocui.View) error {
+			app.stopStream()
+			return nil
+		}))
 	if err != nil {
 		log.Panicln(err)
 	}
@@ -264,17 +499,82 @@ func (app *monitorApp) bindKeys() {
 		log.Panicln(err)
 	}
 	err = app.gui.SetKeybinding("", gocui.KeyEnter, gocui.ModNone,
-		func(_ *gocui.Gui, _ *gocui.View) error {
+		app.whenNoPopup(func(_ *gocui.Gui, _ *gocui.View) error {
+			if app.gui.CurrentView() == app.panelDetail {
+				return nil
+			}
+			if app.gui.CurrentView() == app.panelList {
+				if app.mode == modeGroup {
+					app.drillIntoGroup()
+					return nil
+				}
+				if node := app.selectedNode(); node != nil && node.parent {
+					app.expandNode(!node.open)
+					return nil
+				}
+				if app.descend() {
+					return nil
+				}
+			}
+			app.checkFilter()
+			app.remember(app.queries, app.panelQuery)
+			app.remember(app.filters, app.panelFilter)
 			app.doQuery()
 			app.redrawList()
 			app.redrawTable()
 			return nil
-		})
+		}))
 	if err != nil {
 		log.Panicln(err)
 	}
 
+	app.bindPopupKeys()
+	app.bindExplorerKeys()
+	app.bindDetailKeys()
+	app.bindRecallKeys(app.panelQuery, app.queries)
+	app.bindRecallKeys(app.panelFilter, app.filters)
+	app.bindCompletionKeys()
+	app.bindFilterKeys()
+	app.bindPresetKeys()
+	app.bindWidthKeys()
+
 	// Specific bindings for the list panel
+	err = app.gui.SetKeybinding(app.panelList.Name(), gocui.KeyArrowRight, gocui.ModNone,
+		func(_ *gocui.Gui, v *gocui.View) error {
+			app.expandNode(true)
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding(app.panelList.Name(), gocui.KeyArrowLeft, gocui.ModNone,
+		func(_ *gocui.Gui, v *gocui.View) error {
+			app.expandNode(false)
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding(app.panelList.Name(), '/', gocui.ModNone,
+		func(_ *gocui.Gui, v *gocui.View) error {
+			app.promptSearch()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	for _, key := range []gocui.Key{gocui.KeyBackspace, gocui.KeyBackspace2} {
+		err = app.gui.SetKeybinding(app.panelList.Name(), key, gocui.ModNone,
+			func(_ *gocui.Gui, v *gocui.View) error {
+				if !app.leaveGroup() {
+					app.ascend()
+				}
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
 	err = app.gui.SetKeybinding(app.panelList.Name(), gocui.KeyArrowUp, gocui.ModNone,
 		func(_ *gocui.Gui, v *gocui.View) error {
 			app.panelList.MoveCursor(0, -1, false)
@@ -330,12 +630,12 @@ func (app *monitorApp) dimensionError() (x0, y0, x1, y1 int) {
 
 func (app *monitorApp) dimensionList() (x0, y0, x1, y1 int) {
 	_, maxY := app.gui.Size()
-	return 0, heightQuery + 2 + heightFilter + 2, widthList, maxY - 1
+	return 0, heightQuery + 2 + heightFilter + 2, app.listWidth, maxY - 1
 }
 
 func (app *monitorApp) dimensionDetail() (x0, y0, x1, y1 int) {
 	maxX, maxY := app.gui.Size()
-	return widthList + 1, heightQuery + 2 + heightFilter + 2, maxX - 1, maxY - 1
+	return app.listWidth + 1, heightQuery + 2 + heightFilter + 2, maxX - 1, maxY - 1
 }
 
 func (app *monitorApp) layoutQuery() error {
@@ -355,8 +655,9 @@ func (app *monitorApp) layoutError() error {
 	}
 
 	v.Clear()
-	if app.err != nil {
-		fmt.Fprint(v, app.err.Error())
+	_, height := v.Size()
+	for _, line := range fitErrorLines(height, app.err, app.filterErr) {
+		fmt.Fprintln(v, line)
 	}
 	return nil
 }
@@ -381,6 +682,10 @@ func (app *monitorApp) layoutDetail() error {
 }
 
 func (app *monitorApp) layout() error {
+	if err := app.layoutPopup(); err != nil {
+		log.Panicf("popup layout: %v", err)
+		return err
+	}
 	if err := app.layoutQuery(); err != nil {
 		log.Panicf("query layout: %v", err)
 		return err
@@ -455,143 +760,423 @@ func (app *monitorApp) choosePanel(panel *gocui.View) {
 		log.Panicf("Unexpected panel %s", panel.Name())
 	}
 	app.panelQuery.BgColor = gocui.ColorDefault
+	app.panelFilter.BgColor = gocui.ColorDefault
 	app.panelError.BgColor = gocui.ColorDefault
 	app.panelList.BgColor = gocui.ColorDefault
 	app.panelDetail.BgColor = gocui.ColorDefault
 	panel.BgColor = gocui.ColorCyan
 }
 
-func (app *monitorApp) getKeyName(i int) string {
+func (app *monitorApp) getKeyName(i int) string { return app.itemKeyName(app.rows[i]) }
+
+func (app *monitorApp) getKeyValue(i int) string { return app.itemKeyValue(app.rows[i]) }
+
+func (app *monitorApp) itemKeyName(item MonitoredItem) string {
 	if app.currentKey != "" {
 		return app.currentKey
 	} else {
-		return app.items[i].GetPrimaryKey()
+		return item.GetPrimaryKey()
 	}
 }
 
-func (app *monitorApp) getKeyValue(i int) string { return app.items[i].GetValue(app.getKeyName(i)) }
+func (app *monitorApp) itemKeyValue(item MonitoredItem) string {
+	return item.GetValue(app.itemKeyName(item))
+}
+
+func (app *monitorApp) lessItems(item0, item1 MonitoredItem) bool {
+	return 0 > strings.Compare(app.itemKeyValue(item0), app.itemKeyValue(item1))
+}
 
 func (app *monitorApp) doQuery() {
+	formerQuery := app.query
 	app.query = app.panelQuery.Buffer()
 	app.query = strings.Trim(app.query, "  \r\n\t")
-	items, err := app.source.FetchAll(app.query)
-	if err != nil {
-		app.err = err
-		app.items = []MonitoredItem{}
+
+	// A refresh of the same query goes back to the same level, and keeps the baseline
+	path := app.resetLevels()
+	if app.query != formerQuery {
+		path = nil
+		app.baseline, app.baselinePath = nil, ""
+	}
+
+	// Only a refresh of the same query is compared with the former items
+	app.previous = nil
+	app.changes = nil
+	if app.query == formerQuery && app.knownKeys != nil {
+		app.previous = indexItems(app.items)
 	} else {
+		app.history = nil
+	}
+
+	app.cancelStream()
+	app.cancelWatch()
+	app.treeCache = nil
+	app.streamStopped = false
+	app.marks = nil
+	app.items = []MonitoredItem{}
+	app.knownKeys = make(map[string]bool)
+	app.possibleKeys = make([]string, 0)
+	if streaming, ok := app.source.(StreamingMonitorable); ok {
 		app.err = nil
+		app.startStream(streaming)
+		return
+	}
+
+	items, err := app.source.FetchAll(app.query)
+	// A partial failure still brings items that deserve to be displayed
+	app.err = err
+	if items != nil {
 		app.items = items
 	}
+	app.addPossibleKeys(app.items)
+	app.items = append(app.items, app.diffItems()...)
+	app.recordHistory()
+	app.evaluateAlerts()
 
-	// Extract the possible keys
-	possibleKeys := make(map[string]bool)
-	for _, item := range app.items {
-		possibleKeys[item.GetPrimaryKey()] = true
-		for _, k := range item.GetKeys() {
-			possibleKeys[k] = true
+	// Sort the item according to the selected key
+	sort.Slice(app.items, func(idx0, idx1 int) bool { return app.lessItems(app.items[idx0], app.items[idx1]) })
+	app.updateRows()
+
+	if watchable, ok := app.source.(WatchableMonitorable); ok && err == nil {
+		app.startWatch(watchable)
+	}
+	if len(path) > 0 {
+		app.redescend(path)
+		app.updateRows()
+	}
+}
+
+// shiftCurrentKey selects the next or the previous key among the possible keys, the primary key of each item
+// being the key before the first and after the last.
+func (app *monitorApp) shiftCurrentKey(shift int) {
+	index := -1
+	for i, k := range app.possibleKeys {
+		if k == app.currentKey {
+			index = i
 		}
 	}
-	app.possibleKeys = make([]string, 0)
-	for k, _ := range possibleKeys {
-		app.possibleKeys = append(app.possibleKeys, k)
+	index += shift
+	if index < -1 {
+		index = len(app.possibleKeys) - 1
+	} else if index >= len(app.possibleKeys) {
+		index = -1
+	}
+	if index < 0 {
+		app.currentKey = ""
+	} else {
+		app.currentKey = app.possibleKeys[index]
 	}
-	app.possibleKeys.Sort()
+	app.resortItems()
+}
 
-	// Sort the item according to the selected key
-	less := func(idx0, idx1 int) bool { return 0 > strings.Compare(app.getKeyValue(idx0), app.getKeyValue(idx1)) }
-	sort.Slice(app.items, less)
+// resortItems sorts the items after a change of the current key, the cursor stays on the same item.
+func (app *monitorApp) resortItems() {
+	selected := app.selectedItem()
+	sort.SliceStable(app.items, func(idx0, idx1 int) bool { return app.lessItems(app.items[idx0], app.items[idx1]) })
+	app.refreshList(selected)
 }
 
-func (app *monitorApp) redrawDetail() {
-	_, cy := app.panelList.Cursor()
-	_, oy := app.panelList.Origin()
-	index := cy + oy
+// addPossibleKeys extracts the possible keys of the given items
+func (app *monitorApp) addPossibleKeys(items []MonitoredItem) {
+	added := false
+	for _, item := range items {
+		for _, k := range append(item.GetKeys(), item.GetPrimaryKey()) {
+			if !app.knownKeys[k] {
+				app.knownKeys[k] = true
+				app.possibleKeys = append(app.possibleKeys, k)
+				added = true
+			}
+		}
+	}
+	if added {
+		app.possibleKeys.Sort()
+	}
+}
 
-	var current MonitoredItem
-	if index < len(app.items) {
-		current = app.items[index]
+// mergeItems inserts the given items in the list, preserving the sort order
+func (app *monitorApp) mergeItems(items []MonitoredItem) {
+	sort.SliceStable(items, func(idx0, idx1 int) bool { return app.lessItems(items[idx0], items[idx1]) })
+	merged := make([]MonitoredItem, 0, len(app.items)+len(items))
+	i, j := 0, 0
+	for i < len(app.items) && j < len(items) {
+		if app.lessItems(items[j], app.items[i]) {
+			merged = append(merged, items[j])
+			j++
+		} else {
+			merged = append(merged, app.items[i])
+			i++
+		}
 	}
+	merged = append(merged, app.items[i:]...)
+	merged = append(merged, items[j:]...)
+	app.items = merged
+	app.addPossibleKeys(items)
+}
+
+func (app *monitorApp) redrawDetail() {
+	current := app.selectedItem()
+	app.keepDetailScroll(current)
 
+	if app.mode == modeStats {
+		app.redrawStats()
+	}
+	if app.mode == modeExplore {
+		app.redrawExplorer()
+	}
+	if app.mode == modeCompare {
+		app.redrawCompare()
+	}
 	if app.mode == modeDetail {
 		app.panelDetail.Clear()
+		app.panelDetail.Title = "Detail"
 		if current != nil {
-			fmt.Fprintf(app.panelDetail, "%v", current.GetDetail())
+			if _, ok := current.(StructuredDetail); ok {
+				app.panelDetail.Title = fmt.Sprintf("Detail (%v)", app.detailFormat)
+			}
+			var b strings.Builder
+			writeDetail(&b, current, app.detailFormat)
+			app.writeChanges(&b, current)
+			app.writeRates(&b, current)
+			if app.currentKey != "" {
+				writeChart(&b, app.currentKey, app.samplesOf(current, app.currentKey, historyDepth))
+			}
+			app.writeDetailText(b.String())
 		}
 	}
 }
 
 func (app *monitorApp) redrawList() {
+	app.updateRows()
+	app.renderList()
+
+	if app.err == nil {
+		app.choosePanel(app.panelList)
+		app.panelList.SetOrigin(0, 0)
+		app.panelList.SetCursor(0, 0)
+	}
+	app.redrawDetail()
+}
+
+// renderList writes the items in the list panel, without moving the cursor
+func (app *monitorApp) renderList() {
+	if app.mode == modeGroup {
+		app.renderGroups()
+		return
+	}
 	app.panelList.Clear()
-	app.panelDetail.Clear()
 	separator := ""
-	for _, item := range app.items {
-		k := app.currentKey
-		if k == "" {
-			k = item.GetPrimaryKey()
+	for i, item := range app.rows {
+		prefix := ""
+		if app.treeMode && i < len(app.treeRows) {
+			prefix = app.treeRows[i].prefix
+		}
+		if style := app.rowStyle(item); style != "" {
+			fmt.Fprintf(app.panelList, "%s%s%s%v%s", separator, prefix, style, app.itemKeyValue(item), ansiReset)
+		} else {
+			fmt.Fprintf(app.panelList, "%s%s%v", separator, prefix, app.itemKeyValue(item))
 		}
-		fmt.Fprintf(app.panelList, "%s%v", separator, item.GetValue(k))
 		separator = "\n"
 	}
+	app.panelList.Title = app.listTitle()
+}
 
-	if app.err == nil {
-		app.choosePanel(app.panelList)
-		app.panelList.SetOrigin(0, 0)
-		app.panelList.SetCursor(0, 0)
+func (app *monitorApp) listTitle() string {
+	label := "Objects"
+	if app.currentKey != "" {
+		label = app.currentKey
+	}
+	if len(app.levels) > 0 {
+		label = app.breadcrumb()
+	}
+	if app.rowFilter != nil {
+		label = app.rowFilter.String()
+	}
+	if app.search != "" {
+		label = fmt.Sprintf("%s /%s", label, app.search)
+		if app.treeMode && app.searchPartial {
+			// Some children were not fetched, within the bounds of the search
+			label += " (partial)"
+		}
+	}
+	count := fmt.Sprint(len(app.rows))
+	if len(app.rows) != len(app.items) && !app.treeMode {
+		count = fmt.Sprintf("%d/%d", len(app.rows), len(app.items))
+	}
+	if len(app.alerts) > 0 {
+		label = fmt.Sprintf("%s !%d", label, len(app.alerts))
+	}
+	if app.baseline != nil && len(app.levels) == 0 {
+		label = fmt.Sprintf("%s vs %s", label, filepath.Base(app.baselinePath))
+	}
+	switch {
+	case app.stream != nil:
+		return fmt.Sprintf("%s (%s...)", label, count)
+	case app.streamStopped:
+		return fmt.Sprintf("%s (%s, stopped)", label, count)
+	default:
+		return fmt.Sprintf("%s (%s)", label, count)
+	}
+}
+
+// updateRows selects the items displayed in the list
+func (app *monitorApp) updateRows() {
+	if app.treeMode {
+		app.updateTreeRows()
+		return
+	}
+	if app.rowFilter == nil && app.search == "" {
+		app.rows = app.items
+		return
+	}
+	app.rows = make([]MonitoredItem, 0)
+	for _, item := range app.items {
+		if app.rowFilter != nil && !app.rowFilter.accept(item) {
+			continue
+		}
+		if app.search != "" && !app.matchesSearch(item) {
+			continue
+		}
+		app.rows = append(app.rows, item)
 	}
 }
 
+// selectedItem returns the item under the cursor of the list panel, or nil if there is none
+func (app *monitorApp) selectedItem() MonitoredItem {
+	if app.mode == modeGroup {
+		return nil
+	}
+	index := app.selectedIndex()
+	if index < 0 || index >= len(app.rows) {
+		return nil
+	}
+	return app.rows[index]
+}
+
+// listLength returns the number of lines in the list panel
+func (app *monitorApp) listLength() int {
+	if app.mode == modeGroup {
+		return len(app.groups)
+	}
+	return len(app.rows)
+}
+
+func (app *monitorApp) selectedIndex() int {
+	_, cy := app.panelList.Cursor()
+	_, oy := app.panelList.Origin()
+	return cy + oy
+}
+
+// selectIndex moves the cursor of the list panel on the given item, and scrolls only if necessary
+func (app *monitorApp) selectIndex(index int) { showLine(app.panelList, index) }
+
+// showLine moves the cursor of a panel on the given line, and scrolls only if necessary
+func showLine(v *gocui.View, index int) {
+	if index < 0 {
+		index = 0
+	}
+	_, vy := v.Size()
+	_, oy := v.Origin()
+	if index < oy || index >= oy+vy {
+		oy = index - vy/2
+		if oy < 0 {
+			oy = 0
+		}
+	}
+	if err := v.SetOrigin(0, oy); err != nil {
+		log.Panicf("select origin: %v", err)
+	}
+	if err := v.SetCursor(0, index-oy); err != nil {
+		log.Panicf("select cursor: %v", err)
+	}
+}
+
+// refreshList redraws the list, keeping the cursor on the same item if it is still present.
+func (app *monitorApp) refreshList(selected MonitoredItem) {
+	app.updateRows()
+	index := app.selectedIndex()
+	for i, item := range app.rows {
+		if item == selected {
+			index = i
+			break
+		}
+	}
+	if index >= app.listLength() {
+		index = app.listLength() - 1
+	}
+	app.renderList()
+	app.selectIndex(index)
+	app.redrawTable()
+	app.redrawDetail()
+}
+
+// setMode switches the content of the detail panel, and the list panel between items and groups
+func (app *monitorApp) setMode(mode detailMode) {
+	groupsChanged := app.mode == modeGroup || mode == modeGroup
+	if mode == modeGroup && app.mode != modeGroup {
+		app.groupReturnMode = app.mode
+	}
+	app.mode = mode
+	app.panelDetail.Highlight = mode == modeTable || mode == modeGroup || mode == modeExplore
+	app.panelDetail.Title = "Detail"
+	app.panelDetail.Wrap = app.wrapDetail && mode != modeTable && mode != modeGroup
+	if err := app.panelDetail.SetOrigin(0, 0); err != nil {
+		log.Panicf("reset detail origin: %v", err)
+	}
+	if groupsChanged {
+		app.updateRows()
+		app.renderList()
+		app.selectIndex(0)
+	}
+	app.redrawTable()
+	app.redrawDetail()
+}
+
 func (app *monitorApp) redrawTable() {
+	if app.mode == modeGroup {
+		app.redrawGroupTable()
+		return
+	}
 	if app.mode != modeTable {
 		return
 	}
 	app.panelDetail.Clear()
-	w := tabwriter.NewWriter(app.panelDetail, 8, 1, 2, ' ', 0)
-
-	// Prepare the key patterns
-	csp := app.panelFilter.ViewBuffer()
-	patterns := strings.Split(csp, ",")
-	for i, p := range patterns {
-		patterns[i] = strings.Trim(p, " \t\n\r")
-	}
-	matchers := make([]*regexp.Regexp, 0)
-	for _, pattern := range patterns {
-		r, _ := regexp.Compile(pattern)
-		matchers = append(matchers, r)
-	}
-	matches := func(k string) bool {
-		for _, m := range matchers {
-			if m.MatchString(k) {
-				return true
-			}
-		}
-		return false
-	}
 
-	crlf := ""
-	for i, item := range app.items {
-		fmt.Fprint(w, crlf)
-		crlf = "\n"
+	selector := app.keySelector()
 
+	rows := make([][]tableCell, 0, len(app.rows))
+	for i, item := range app.rows {
 		currentKey := app.getKeyName(i)
-		separator := ""
-		for _, k := range item.GetKeys() {
-			if k == currentKey || !matches(k) {
+		former := app.previousOf(item)
+		row := make([]tableCell, 0)
+		for _, k := range selector.columns(item.GetKeys()) {
+			if k == currentKey {
 				continue
 			}
-			fmt.Fprintf(w, "%s%s", separator, item.GetValue(k))
-			separator = "\t"
+			value := item.GetValue(k)
+			cell := tableCell{text: app.counterText(item, k, value), style: app.alertCellStyle(item, k)}
+			if cell.style == "" {
+				cell.style = app.cellStyle(former, k, value)
+			}
+			if spark := sparkline(app.samplesOf(item, k, sparklineWidth)); spark != "" {
+				cell.text += " " + spark
+			}
+			// The fixed width includes the sparkline
+			cell.text = fitWidth(cell.text, app.widths[k])
+			row = append(row, cell)
 		}
+		rows = append(rows, row)
 	}
-	w.Flush()
+	writeTable(app.panelDetail, rows)
 }
 
 func (app *monitorApp) alignTableOnList() {
-	if app.mode != modeTable {
+	if app.mode != modeTable && app.mode != modeGroup {
 		return
 	}
 	_, oy := app.panelList.Origin()
 	_, cy := app.panelList.Cursor()
-	if err := app.panelDetail.SetOrigin(0, oy); err != nil {
+	ox, _ := app.panelDetail.Origin()
+	if err := app.panelDetail.SetOrigin(ox, oy); err != nil {
 		log.Panicf("reset table origin: %v", err)
 	}
 	if err := app.panelDetail.SetCursor(0, cy); err != nil {
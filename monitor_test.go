@@ -0,0 +1,39 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import "strings"
+
+// testItem builds an item whose primary key is "id", from pairs of keys and values
+func testItem(id string, kv ...string) MonitoredItem {
+	rec := snapshotRecord{Primary: "id", Keys: []string{"id"}, Values: map[string]string{"id": id}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		rec.Keys = append(rec.Keys, kv[i])
+		rec.Values[kv[i]] = kv[i+1]
+	}
+	return &snapshotItem{rec}
+}
+
+// newTestApp builds an application without GUI, whose timers are considered as already armed
+func newTestApp(items ...MonitoredItem) *monitorApp {
+	app := &monitorApp{knownKeys: make(map[string]bool), marksTimer: true}
+	app.items = items
+	app.addPossibleKeys(items)
+	return app
+}
+
+// listedIDs renders the primary key values of the items, the ghosts being prefixed with "-"
+func listedIDs(items []MonitoredItem) string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, ok := item.(*removedItem); ok {
+			ids = append(ids, "-"+primaryValue(item))
+		} else {
+			ids = append(ids, primaryValue(item))
+		}
+	}
+	return strings.Join(ids, " ")
+}
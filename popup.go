@@ -0,0 +1,264 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	panelNamePopup  = "popup"
+	widthPopup      = 60
+	heightChoice    = 10
+	heightChecklist = 20
+)
+
+// popup is a transient panel over the others, that captures the focus until it is submitted or cancelled
+type popup struct {
+	view     *gocui.View
+	previous *gocui.View
+	anchor   *gocui.View // The popup is under this panel if set, centered otherwise
+	height   int
+	submit   func(text string)
+
+	// The lines of a popup that is not editable, and their state when they can be ticked
+	choices []string
+	checked []bool
+}
+
+// openPrompt opens a single-line editable popup, the submitted text is passed to the callback
+func (app *monitorApp) openPrompt(title, initial string, submit func(text string)) {
+	app.openPopup(title, 1, true, submit)
+	fmt.Fprint(app.popup.view, initial)
+	if err := app.popup.view.SetCursor(utf8.RuneCountInString(initial), 0); err != nil {
+		log.Panicf("popup cursor: %v", err)
+	}
+}
+
+// openChoice opens a popup listing choices under a panel, the selected one is passed to the callback
+func (app *monitorApp) openChoice(title string, anchor *gocui.View, choices []string, submit func(choice string)) {
+	height := len(choices)
+	if height > heightChoice {
+		height = heightChoice
+	}
+	app.openPopup(title, height, false, submit)
+	app.popup.anchor = anchor
+	app.popup.choices = choices
+	if err := app.layoutPopup(); err != nil {
+		log.Panicln(err)
+	}
+	app.renderChoices()
+	showLine(app.popup.view, 0)
+}
+
+// openChecklist opens a popup where choices are ticked with Space, the ticked ones are passed to the callback
+func (app *monitorApp) openChecklist(title string, choices []string, checked []bool, submit func(ticked []string)) {
+	height := len(choices)
+	if height > heightChecklist {
+		height = heightChecklist
+	}
+	app.openPopup(title, height, false, func(_ string) {
+		ticked := make([]string, 0)
+		for i, choice := range choices {
+			if checked[i] {
+				ticked = append(ticked, choice)
+			}
+		}
+		submit(ticked)
+	})
+	app.popup.choices = choices
+	app.popup.checked = checked
+	app.renderChoices()
+	showLine(app.popup.view, 0)
+}
+
+func (app *monitorApp) renderChoices() {
+	app.popup.view.Clear()
+	for i, choice := range app.popup.choices {
+		if i > 0 {
+			fmt.Fprintln(app.popup.view)
+		}
+		switch {
+		case app.popup.checked == nil:
+			fmt.Fprint(app.popup.view, choice)
+		case app.popup.checked[i]:
+			fmt.Fprint(app.popup.view, "[x] "+choice)
+		default:
+			fmt.Fprint(app.popup.view, "[ ] "+choice)
+		}
+	}
+}
+
+// popupIndex returns the line under the cursor of the popup
+func (app *monitorApp) popupIndex() int {
+	_, cy := app.popup.view.Cursor()
+	_, oy := app.popup.view.Origin()
+	return cy + oy
+}
+
+// toggleChoice ticks or unticks the choice under the cursor of a checklist
+func (app *monitorApp) toggleChoice() {
+	index := app.popupIndex()
+	if app.popup.checked == nil || index >= len(app.popup.checked) {
+		return
+	}
+	app.popup.checked[index] = !app.popup.checked[index]
+	app.renderChoices()
+}
+
+func (app *monitorApp) openPopup(title string, height int, editable bool, submit func(text string)) {
+	if app.popup != nil {
+		app.closePopup()
+	}
+	app.popup = &popup{previous: app.gui.CurrentView(), height: height, submit: submit}
+
+	x0, y0, x1, y1 := app.dimensionPopup()
+	v, err := app.gui.SetView(panelNamePopup, x0, y0, x1, y1)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Panicf("Panel open error: %v", err)
+	}
+	v.Title = title
+	v.Editable = editable
+	v.Editor = gocui.DefaultEditor
+	v.SelBgColor = gocui.ColorYellow
+	v.Highlight = !editable
+	app.popup.view = v
+
+	if _, err = app.gui.SetCurrentView(panelNamePopup); err != nil {
+		log.Panicf("popup focus: %v", err)
+	}
+}
+
+func (app *monitorApp) closePopup() {
+	if app.popup == nil {
+		return
+	}
+	previous := app.popup.previous
+	app.popup = nil
+	if err := app.gui.DeleteView(panelNamePopup); err != nil {
+		log.Panicf("popup close: %v", err)
+	}
+	if previous != nil {
+		app.choosePanel(previous)
+	}
+}
+
+func (app *monitorApp) submitPopup() {
+	if app.popup == nil {
+		return
+	}
+	text := strings.TrimSpace(app.popup.view.Buffer())
+	if index := app.popupIndex(); app.popup.choices != nil && index < len(app.popup.choices) {
+		// The choice under the cursor
+		text = app.popup.choices[index]
+	}
+	submit := app.popup.submit
+	app.closePopup()
+	submit(text)
+}
+
+func (app *monitorApp) dimensionPopup() (x0, y0, x1, y1 int) {
+	maxX, maxY := app.gui.Size()
+	height := app.popup.height
+	if height > maxY-4 {
+		height = maxY - 4
+	}
+	width := widthPopup
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0, y0 = (maxX-width)/2, (maxY-height)/2-1
+	if app.popup.anchor != nil {
+		ax0, _, _, ay1, err := app.gui.ViewPosition(app.popup.anchor.Name())
+		if err != nil {
+			log.Panicf("popup anchor: %v", err)
+		}
+		x0, y0 = ax0, ay1
+		if y0+height+1 >= maxY {
+			height = maxY - y0 - 2
+		}
+	}
+	return x0, y0, x0 + width, y0 + height + 1
+}
+
+func (app *monitorApp) layoutPopup() error {
+	if app.popup == nil {
+		return nil
+	}
+	x0, y0, x1, y1 := app.dimensionPopup()
+	if _, err := app.gui.SetView(panelNamePopup, x0, y0, x1, y1); err != nil {
+		log.Panicln(err)
+	}
+	return nil
+}
+
+// whenNoPopup disables the handler of a global binding while a popup is open
+func (app *monitorApp) whenNoPopup(handler func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if app.popup != nil {
+			return nil
+		}
+		return handler(g, v)
+	}
+}
+
+func (app *monitorApp) bindPopupKeys() {
+	err := app.gui.SetKeybinding(panelNamePopup, gocui.KeyEnter, gocui.ModNone,
+		func(_ *gocui.Gui, _ *gocui.View) error {
+			app.submitPopup()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	for key, step := range map[gocui.Key]int{gocui.KeyArrowUp: -1, gocui.KeyArrowDown: 1} {
+		step := step
+		err = app.gui.SetKeybinding(panelNamePopup, key, gocui.ModNone,
+			func(_ *gocui.Gui, v *gocui.View) error {
+				if next := app.popupIndex() + step; !v.Editable && next >= 0 && next < len(app.popup.choices) {
+					showLine(v, next)
+				}
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+	err = app.gui.SetKeybinding(panelNamePopup, gocui.KeySpace, gocui.ModNone,
+		func(_ *gocui.Gui, v *gocui.View) error {
+			// The binding prevails over the editor of the prompts
+			if v.Editable {
+				v.EditWrite(' ')
+			} else {
+				app.toggleChoice()
+			}
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding(panelNamePopup, gocui.KeyEsc, gocui.ModNone,
+		func(_ *gocui.Gui, _ *gocui.View) error {
+			app.closePopup()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding(panelNamePopup, gocui.KeyCtrlG, gocui.ModNone,
+		func(_ *gocui.Gui, _ *gocui.View) error {
+			app.closePopup()
+			return nil
+		})
+	if err != nil {
+		log.Panicln(err)
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// Preset is a named view of the monitor, that restores at once the query, the filter of the columns, the
+// restriction of the rows, the sort key, the mode and the widths of the columns.
+// An empty Query keeps the current query, the other empty fields reset their state. Mode is one of "detail",
+// "table", "group" and "stats". Group is a group-by specification like "mode count sum:size", Where restricts the
+// list to a group like "mode=0644" and Search to the items containing a text. ListWidth is the width of the list
+// panel and Widths the fixed widths of the columns of the table, by key.
+type Preset struct {
+	Name      string         `json:"name"`
+	Query     string         `json:"query,omitempty"`
+	Filter    string         `json:"filter,omitempty"`
+	Search    string         `json:"search,omitempty"`
+	Group     string         `json:"group,omitempty"`
+	Where     string         `json:"where,omitempty"`
+	Sort      string         `json:"sort,omitempty"`
+	Mode      string         `json:"mode,omitempty"`
+	ListWidth int            `json:"list_width,omitempty"`
+	Widths    map[string]int `json:"widths,omitempty"`
+}
+
+// PresetMonitorable is an optional interface of a Monitorable that proposes its own presets. They are listed
+// before the presets of the configuration file, that replace the ones with the same name.
+type PresetMonitorable interface {
+	Monitorable
+
+	// Presets returns the presets, the first nine being selected with the number keys in the list panel
+	Presets() []Preset
+}
+
+// modeNames are the names of the modes in the presets
+var modeNames = map[detailMode]string{
+	modeDetail:  "detail",
+	modeTable:   "table",
+	modeGroup:   "group",
+	modeStats:   "stats",
+	modeExplore: "explore",
+	modeCompare: "compare",
+}
+
+// presetsPath returns the configuration file of the presets, following the XDG specification
+func presetsPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "cui", filepath.Base(os.Args[0]), "presets.json"), nil
+}
+
+// loadPresets reads the presets of the configuration file. A missing file has no preset.
+func loadPresets() ([]Preset, error) {
+	path, err := presetsPath()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("presets: %w", err)
+	}
+	var presets []Preset
+	if err = json.Unmarshal(encoded, &presets); err != nil {
+		return nil, fmt.Errorf("presets %s: %w", path, err)
+	}
+	return presets, nil
+}
+
+// savePresets writes the presets in the configuration file, replacing it atomically
+func savePresets(presets []Preset) error {
+	path, err := presetsPath()
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(presets, "", " ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("presets: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, encoded, 0644); err != nil {
+		return fmt.Errorf("presets: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("presets: %w", err)
+	}
+	return nil
+}
+
+// mergePresets appends the presets to the list, replacing the ones with the same name
+func mergePresets(presets []Preset, more ...Preset) []Preset {
+	for _, p := range more {
+		replaced := false
+		for i := range presets {
+			if presets[i].Name == p.Name {
+				presets[i], replaced = p, true
+				break
+			}
+		}
+		if !replaced {
+			presets = append(presets, p)
+		}
+	}
+	return presets
+}
+
+// findPreset returns the index of the preset with the given name, or -1
+func findPreset(presets []Preset, name string) int {
+	for i, p := range presets {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentPreset captures the state of the monitor. The modes depending on the selected item are saved as the
+// detail mode.
+func (app *monitorApp) currentPreset(name string) Preset {
+	p := Preset{
+		Name:      name,
+		Query:     app.query,
+		Filter:    inputText(app.panelFilter),
+		Search:    app.search,
+		Sort:      app.currentKey,
+		Mode:      modeNames[app.mode],
+		ListWidth: app.listWidth,
+	}
+	if app.mode == modeExplore || app.mode == modeCompare {
+		p.Mode = modeNames[modeDetail]
+	}
+	if app.groupSpec.key != "" {
+		p.Group = app.groupSpec.String()
+	}
+	if app.rowFilter != nil {
+		p.Where = app.rowFilter.String()
+	}
+	if len(app.widths) > 0 {
+		p.Widths = make(map[string]int, len(app.widths))
+		for k, w := range app.widths {
+			p.Widths[k] = w
+		}
+	}
+	return p
+}
+
+// parsePreset checks a preset, and returns its mode, its group-by specification and its restriction of the rows.
+// The restriction alone implies a count of the items grouped by its key.
+func parsePreset(p Preset) (detailMode, groupSpec, *rowFilter, error) {
+	mode := modeDetail
+	var spec groupSpec
+	var filter *rowFilter
+	var err error
+	if p.Mode != "" {
+		found := false
+		for m, name := range modeNames {
+			if name == p.Mode && m != modeExplore && m != modeCompare {
+				mode, found = m, true
+			}
+		}
+		if !found {
+			return mode, spec, filter, fmt.Errorf("preset %s: unknown mode %q", p.Name, p.Mode)
+		}
+	}
+	if p.Group != "" {
+		if spec, err = parseGroupSpec(p.Group); err != nil {
+			return mode, spec, filter, fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+	}
+	if p.Where != "" {
+		key, value, ok := strings.Cut(p.Where, "=")
+		if !ok {
+			return mode, spec, filter, fmt.Errorf("preset %s: invalid restriction %q, expected key=value",
+				p.Name, p.Where)
+		}
+		filter = &rowFilter{key: key, value: value}
+		if spec.key == "" {
+			spec = groupSpec{key: key, aggregates: []aggregate{{fn: "count"}}}
+		} else if spec.key != key {
+			return mode, spec, filter, fmt.Errorf("preset %s: the restriction %q is not on the grouping key %q",
+				p.Name, p.Where, spec.key)
+		}
+	}
+	if mode == modeGroup && spec.key == "" {
+		return mode, spec, filter, fmt.Errorf("preset %s: no group-by specification", p.Name)
+	}
+	for k, w := range p.Widths {
+		if w < 0 {
+			return mode, spec, filter, fmt.Errorf("preset %s: negative width of %s", p.Name, k)
+		}
+	}
+	return mode, spec, filter, nil
+}
+
+// applyPreset restores the state of a preset, and fetches the items again only if the query changes
+func (app *monitorApp) applyPreset(p Preset) error {
+	mode, spec, filter, err := parsePreset(p)
+	if err != nil {
+		return err
+	}
+
+	app.resetLevels()
+	app.preset = p.Name
+	app.currentKey = p.Sort
+	app.search = p.Search
+	app.groupSpec = spec
+	app.rowFilter = filter
+	app.widths = p.Widths
+	app.listWidth = widthList
+	if p.ListWidth > 0 {
+		app.listWidth = p.ListWidth
+	}
+	filterText := p.Filter
+	if filterText == "" {
+		filterText = "*"
+	}
+	setInput(app.panelFilter, filterText)
+	app.checkFilter()
+
+	query := strings.TrimSpace(p.Query)
+	if query != "" && query != app.query {
+		setInput(app.panelQuery, query)
+		app.doQuery()
+	} else {
+		app.resortItems()
+	}
+	app.setMode(mode)
+	app.redrawList()
+	app.redrawTable()
+	return nil
+}
+
+// selectPreset applies the preset at the given position
+func (app *monitorApp) selectPreset(index int) {
+	if index < 0 || index >= len(app.presets) {
+		return
+	}
+	app.err = app.applyPreset(app.presets[index])
+}
+
+// pickPreset lists the presets in a popup, the number keys selecting the first ones
+func (app *monitorApp) pickPreset() {
+	if len(app.presets) == 0 {
+		app.err = errors.New("no preset, save one with Alt-V")
+		return
+	}
+	labels := make([]string, 0, len(app.presets))
+	for i, p := range app.presets {
+		label := "   " + p.Name
+		if i < 9 {
+			label = fmt.Sprintf("%d  %s", i+1, p.Name)
+		}
+		labels = append(labels, label)
+	}
+	app.openChoice("Presets", nil, labels, func(choice string) {
+		for i, label := range labels {
+			if label == choice {
+				app.selectPreset(i)
+				return
+			}
+		}
+	})
+	if i := findPreset(app.presets, app.preset); i >= 0 {
+		showLine(app.popup.view, i)
+	}
+}
+
+// promptSavePreset asks for the name of the preset capturing the current state, and saves it in the
+// configuration file
+func (app *monitorApp) promptSavePreset() {
+	app.openPrompt("Save the view as", app.preset, func(name string) {
+		if name == "" {
+			return
+		}
+		p := app.currentPreset(name)
+		saved, err := loadPresets()
+		if err == nil {
+			err = savePresets(mergePresets(saved, p))
+		}
+		app.err = err
+		app.presets = mergePresets(app.presets, p)
+		app.preset = name
+	})
+}
+
+func (app *monitorApp) bindPresetKeys() {
+	err := app.gui.SetKeybinding("", 'v', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, _ *gocui.View) error {
+			app.pickPreset()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	err = app.gui.SetKeybinding("", 'V', gocui.ModAlt,
+		app.whenNoPopup(func(_ *gocui.Gui, _ *gocui.View) error {
+			app.promptSavePreset()
+			return nil
+		}))
+	if err != nil {
+		log.Panicln(err)
+	}
+	for i := 0; i < 9; i++ {
+		index := i
+		err = app.gui.SetKeybinding(app.panelList.Name(), rune('1'+i), gocui.ModNone,
+			func(_ *gocui.Gui, _ *gocui.View) error {
+				app.selectPreset(index)
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWidths(t *testing.T) {
+	cases := []struct {
+		text  string
+		want  map[string]int
+		fails bool
+	}{
+		{text: "", want: map[string]int{}},
+		{text: "path=30 size=10", want: map[string]int{"path": 30, "size": 10}},
+		{text: "  path=0  ", want: map[string]int{"path": 0}},
+		{text: "a=b=3", fails: true},
+		{text: "path", fails: true},
+		{text: "=3", fails: true},
+		{text: "path=-1", fails: true},
+		{text: "path=x", fails: true},
+	}
+	for _, c := range cases {
+		got, err := parseWidths(c.text)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", c.text, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %v and %v, expected %v", c.text, got, err, c.want)
+			continue
+		}
+		if again, _ := parseWidths(formatWidths(got)); !reflect.DeepEqual(again, got) {
+			t.Errorf("%q: formatted as %q", c.text, formatWidths(got))
+		}
+	}
+	if got := formatWidths(map[string]int{"size": 10, "path": 30}); got != "path=30 size=10" {
+		t.Errorf("formatted as %q", got)
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	cases := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"abc", 0, "abc"},
+		{"abc", 3, "abc"},
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"élément", 3, "él…"},
+	}
+	for _, c := range cases {
+		if got := fitWidth(c.text, c.width); got != c.want {
+			t.Errorf("%q on %d: %q instead of %q", c.text, c.width, got, c.want)
+		}
+	}
+}
+
+func TestClampListWidth(t *testing.T) {
+	cases := []struct{ width, maxX, want int }{
+		{20, 200, 20},
+		{2, 200, listMinWidth},
+		{500, 200, listMaxWidth},
+		{100, 80, 80 - listMinWidth},
+		{20, 10, listMinWidth},
+	}
+	for _, c := range cases {
+		if got := clampListWidth(c.width, c.maxX); got != c.want {
+			t.Errorf("%d in %d: %d instead of %d", c.width, c.maxX, got, c.want)
+		}
+	}
+}
+
+func TestMergePresets(t *testing.T) {
+	presets := []Preset{{Name: "a", Query: "1"}, {Name: "b", Query: "2"}}
+	presets = mergePresets(presets, Preset{Name: "b", Query: "3"}, Preset{Name: "c"}, Preset{Name: "a", Query: "4"})
+	want := []Preset{{Name: "a", Query: "4"}, {Name: "b", Query: "3"}, {Name: "c"}}
+	if !reflect.DeepEqual(presets, want) {
+		t.Fatalf("got %+v", presets)
+	}
+	if findPreset(presets, "c") != 2 || findPreset(presets, "z") != -1 {
+		t.Error("unexpected positions")
+	}
+}
+
+func TestParsePreset(t *testing.T) {
+	cases := []struct {
+		preset Preset
+		mode   detailMode
+		group  string
+		where  string
+		fails  bool
+	}{
+		{preset: Preset{}, mode: modeDetail},
+		{preset: Preset{Mode: "table"}, mode: modeTable},
+		{preset: Preset{Mode: "group", Group: "mode count sum:size"}, mode: modeGroup, group: "mode count sum:size"},
+		{preset: Preset{Mode: "group", Where: "mode=0644"}, mode: modeGroup, group: "mode count", where: "mode=0644"},
+		{preset: Preset{Group: "mode sum:size", Where: "mode=0644"}, group: "mode sum:size", where: "mode=0644"},
+		{preset: Preset{Group: "mode count", Where: "owner=root"}, fails: true},
+		{preset: Preset{Where: "owner"}, fails: true},
+		{preset: Preset{Mode: "group"}, fails: true},
+		{preset: Preset{Mode: "explore"}, fails: true},
+		{preset: Preset{Mode: "bogus"}, fails: true},
+		{preset: Preset{Widths: map[string]int{"size": -1}}, fails: true},
+	}
+	for _, c := range cases {
+		mode, spec, filter, err := parsePreset(c.preset)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%+v: expected an error", c.preset)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", c.preset, err)
+			continue
+		}
+		group, where := "", ""
+		if spec.key != "" {
+			group = spec.String()
+		}
+		if filter != nil {
+			where = filter.String()
+		}
+		if mode != c.mode || group != c.group || where != c.where {
+			t.Errorf("%+v: got %v %q %q", c.preset, mode, group, where)
+		}
+	}
+}
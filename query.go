@@ -0,0 +1,37 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"errors"
+	"strings"
+)
+
+// splitQueryPath separates the path of a query from the options recognized by isOption. A quoted path is taken
+// verbatim, otherwise the path extends up to the first option, spaces included.
+func splitQueryPath(query string, isOption func(string) bool) (string, []string, error) {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, `"`) {
+		end := strings.Index(query[1:], `"`)
+		if end < 0 {
+			return "", nil, errors.New("unterminated quoted path")
+		}
+		return query[1 : end+1], strings.Fields(query[end+2:]), nil
+	}
+
+	tokens := strings.Fields(query)
+	for i := 1; i < len(tokens); i++ {
+		if isOption(tokens[i]) {
+			// Find the option in the query to keep the spaces of the path
+			offset := 0
+			for _, tok := range tokens[:i] {
+				offset = strings.Index(query[offset:], tok) + offset + len(tok)
+			}
+			return strings.TrimSpace(query[:offset]), tokens[i:], nil
+		}
+	}
+	return query, nil, nil
+}
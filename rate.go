@@ -0,0 +1,105 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CounterMonitorable is an optional interface of a Monitorable whose items expose monotonically increasing
+// counters. A rate per second is computed for those keys between two fetches. More keys may be marked as
+// counters at runtime with Alt-c.
+type CounterMonitorable interface {
+	Monitorable
+
+	// CounterKeys returns the keys whose values are counters
+	CounterKeys() []string
+}
+
+// rateMode tells how the counters are displayed in the table
+type rateMode int
+
+const (
+	rateAlongside rateMode = iota
+	rateInstead
+	rateHidden
+)
+
+// toggleCounter marks or unmarks the current key as a counter
+func (app *monitorApp) toggleCounter() {
+	if app.currentKey == "" {
+		return
+	}
+	if app.counters == nil {
+		app.counters = make(map[string]bool)
+	}
+	if app.counters[app.currentKey] {
+		delete(app.counters, app.currentKey)
+	} else {
+		app.counters[app.currentKey] = true
+	}
+}
+
+// rateOf computes the rate of a counter between the last two fetches.
+// A decreasing value is considered as a reset of the counter, restarted from zero.
+func (app *monitorApp) rateOf(item MonitoredItem, k string) (float64, bool) {
+	if !app.counters[k] {
+		return 0, false
+	}
+	samples := app.samplesOf(item, k, 2)
+	if len(samples) < 2 {
+		return 0, false
+	}
+	dt := samples[1].at.Sub(samples[0].at).Seconds()
+	if dt <= 0 {
+		return 0, false
+	}
+	delta := samples[1].value - samples[0].value
+	if delta < 0 {
+		delta = samples[1].value
+	}
+	return delta / dt, true
+}
+
+func formatRate(rate float64) string { return formatFloat(rate) + "/s" }
+
+// counterText renders a value of the table according to the rate mode, when it is a counter
+func (app *monitorApp) counterText(item MonitoredItem, k, value string) string {
+	rate, ok := app.rateOf(item, k)
+	if !ok {
+		return value
+	}
+	switch app.rateMode {
+	case rateAlongside:
+		return value + " (" + formatRate(rate) + ")"
+	case rateInstead:
+		return formatRate(rate)
+	default:
+		return value
+	}
+}
+
+// writeRates lists the rates of the counters of the item
+func (app *monitorApp) writeRates(w io.Writer, item MonitoredItem) {
+	keys := make([]string, 0)
+	rates := make(map[string]float64)
+	for _, k := range item.GetKeys() {
+		if rate, ok := app.rateOf(item, k); ok {
+			keys = append(keys, k)
+			rates[k] = rate
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "\n%sRates:%s\n", ansiBold, ansiReset)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s: %s\n", k, formatRate(rates[k]))
+	}
+}
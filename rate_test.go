@@ -0,0 +1,64 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateOf(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	cases := []struct {
+		name    string
+		values  []string
+		elapsed time.Duration
+		counter bool
+		want    float64
+		ok      bool
+	}{
+		{name: "increase", values: []string{"100", "300"}, elapsed: 2 * time.Second, counter: true, want: 100, ok: true},
+		{name: "steady", values: []string{"5", "5"}, elapsed: time.Second, counter: true, want: 0, ok: true},
+		{name: "reset", values: []string{"1000", "30"}, elapsed: 10 * time.Second, counter: true, want: 3, ok: true},
+		{name: "not a counter", values: []string{"1", "2"}, elapsed: time.Second},
+		{name: "single sample", values: []string{"1"}, elapsed: time.Second, counter: true},
+		{name: "same time", values: []string{"1", "2"}, counter: true},
+		{name: "not a number", values: []string{"1", "x"}, elapsed: time.Second, counter: true},
+	}
+	for _, c := range cases {
+		app := newTestApp()
+		app.history = make(map[string]itemHistory)
+		if c.counter {
+			app.counters = map[string]bool{"n": true}
+		}
+		var item MonitoredItem
+		for i, v := range c.values {
+			item = testItem("a", "n", v)
+			app.recordItem(item, t0.Add(time.Duration(i)*c.elapsed), app.history)
+		}
+		rate, ok := app.rateOf(item, "n")
+		if ok != c.ok || rate != c.want {
+			t.Errorf("%s: rate %v %v, expected %v %v", c.name, rate, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestCounterText(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	app := newTestApp()
+	app.history = make(map[string]itemHistory)
+	app.counters = map[string]bool{"n": true}
+	app.recordItem(testItem("a", "n", "10"), t0, app.history)
+	item := testItem("a", "n", "20")
+	app.recordItem(item, t0.Add(4*time.Second), app.history)
+
+	for mode, want := range map[rateMode]string{rateAlongside: "20 (2.5/s)", rateInstead: "2.5/s", rateHidden: "20"} {
+		app.rateMode = mode
+		if got := app.counterText(item, "n", "20"); got != want {
+			t.Errorf("mode %d: %q instead of %q", mode, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+// recallDepth bounds the number of entries kept in the history of an input panel
+const recallDepth = 500
+
+// inputHistory is the persistent history of the texts submitted in an input panel, the most recent last
+type inputHistory struct {
+	path    string
+	entries []string
+	index   int    // The entry displayed while browsing, len(entries) when not browsing
+	draft   string // The text typed before browsing
+}
+
+// stateDir returns the directory of the state files of the application, following the XDG specification
+func stateDir() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "cui", filepath.Base(os.Args[0])), nil
+}
+
+// loadInputHistory reads the history stored under the given name. A missing file is an empty history.
+func loadInputHistory(name string) (*inputHistory, error) {
+	h := &inputHistory{}
+	dir, err := stateDir()
+	if err != nil {
+		return h, err
+	}
+	h.path = filepath.Join(dir, name)
+
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("history: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.push(line)
+		}
+	}
+	h.index = len(h.entries)
+	if err = scanner.Err(); err != nil {
+		return h, fmt.Errorf("history: %w", err)
+	}
+	return h, nil
+}
+
+// push appends an entry, removing its former occurrence and the oldest entries beyond the bound
+func (h *inputHistory) push(entry string) {
+	for i, e := range h.entries {
+		if e == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > recallDepth {
+		h.entries = h.entries[len(h.entries)-recallDepth:]
+	}
+}
+
+// add records a submitted text and saves the history
+func (h *inputHistory) add(entry string) error {
+	entry = strings.TrimSpace(entry)
+	h.draft = ""
+	if entry == "" || strings.ContainsAny(entry, "\r\n") {
+		h.index = len(h.entries)
+		return nil
+	}
+	h.push(entry)
+	h.index = len(h.entries)
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(h.entries, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+// browse moves in the history from the displayed text, towards the older entries with a negative step
+func (h *inputHistory) browse(current string, step int) (string, bool) {
+	if h.index == len(h.entries) {
+		h.draft = current
+	}
+	next := h.index + step
+	if next < 0 || next > len(h.entries) {
+		return "", false
+	}
+	h.index = next
+	if next == len(h.entries) {
+		return h.draft, true
+	}
+	return h.entries[next], true
+}
+
+// search returns the most recent entry older than the displayed one, containing the text typed before browsing
+func (h *inputHistory) search(current string) (string, bool) {
+	if h.index == len(h.entries) {
+		h.draft = current
+	}
+	for i := h.index - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], h.draft) && h.entries[i] != current {
+			h.index = i
+			return h.entries[i], true
+		}
+	}
+	return "", false
+}
+
+// setInput replaces the text of an input panel, with the cursor at the end
+func setInput(v *gocui.View, text string) {
+	v.Clear()
+	fmt.Fprint(v, text)
+	placeCursor(v, utf8.RuneCountInString(text))
+}
+
+// byteOffset converts a position in the runes of a text, like the cursor of an input panel, into an offset in its
+// bytes, bounded by the length of the text
+func byteOffset(text string, pos int) int {
+	for i := range text {
+		if pos <= 0 {
+			return i
+		}
+		pos--
+	}
+	return len(text)
+}
+
+// placeCursor moves the cursor of an input panel at a position in the runes of its text, scrolling it if necessary
+func placeCursor(v *gocui.View, pos int) {
+	width, _ := v.Size()
+	ox := 0
+	if pos >= width {
+		ox = pos - width + 1
+	}
+	if err := v.SetOrigin(ox, 0); err != nil {
+		log.Panicf("input origin: %v", err)
+	}
+	if err := v.SetCursor(pos-ox, 0); err != nil {
+		log.Panicf("input cursor: %v", err)
+	}
+}
+
+// inputText returns the text of an input panel, without the trailing blanks
+func inputText(v *gocui.View) string {
+	return strings.Trim(v.Buffer(), "  \r\n\t")
+}
+
+// remember records the submitted text of an input panel in its history
+func (app *monitorApp) remember(h *inputHistory, v *gocui.View) {
+	if err := h.add(inputText(v)); err != nil && app.err == nil {
+		app.err = err
+	}
+}
+
+// bindRecallKeys binds the browsing of the history of an input panel: Up and Down move among the entries,
+// Ctrl-R searches the older entries containing the text typed before.
+func (app *monitorApp) bindRecallKeys(v *gocui.View, h *inputHistory) {
+	bindings := []struct {
+		key    gocui.Key
+		recall func(current string) (string, bool)
+	}{
+		{gocui.KeyArrowUp, func(current string) (string, bool) { return h.browse(current, -1) }},
+		{gocui.KeyArrowDown, func(current string) (string, bool) { return h.browse(current, 1) }},
+		{gocui.KeyCtrlR, h.search},
+	}
+	for _, b := range bindings {
+		recall := b.recall
+		err := app.gui.SetKeybinding(v.Name(), b.key, gocui.ModNone,
+			func(_ *gocui.Gui, v *gocui.View) error {
+				if text, ok := recall(inputText(v)); ok {
+					setInput(v, text)
+					if v == app.panelFilter {
+						app.checkFilter()
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInputHistoryPush(t *testing.T) {
+	cases := []struct {
+		pushed []string
+		want   string
+	}{
+		{pushed: []string{"a", "b", "c"}, want: "a b c"},
+		{pushed: []string{"a", "b", "a"}, want: "b a"},
+		{pushed: []string{"a", "a", "a"}, want: "a"},
+		{pushed: []string{"a", "b", "c", "b"}, want: "a c b"},
+	}
+	for _, c := range cases {
+		h := &inputHistory{}
+		for _, e := range c.pushed {
+			h.push(e)
+		}
+		if got := strings.Join(h.entries, " "); got != c.want {
+			t.Errorf("%v: %q instead of %q", c.pushed, got, c.want)
+		}
+	}
+
+	h := &inputHistory{}
+	for i := 0; i < recallDepth+10; i++ {
+		h.push(strconv.Itoa(i))
+	}
+	h.push("10")
+	if len(h.entries) != recallDepth || h.entries[0] != "11" || h.entries[len(h.entries)-1] != "10" {
+		t.Errorf("%d entries from %s to %s", len(h.entries), h.entries[0], h.entries[len(h.entries)-1])
+	}
+}
+
+func TestInputHistoryBrowse(t *testing.T) {
+	h := &inputHistory{entries: []string{"a", "b", "c"}, index: 3}
+	steps := []struct {
+		step int
+		want string
+		ok   bool
+	}{
+		{-1, "c", true},
+		{-1, "b", true},
+		{-1, "a", true},
+		{-1, "", false},
+		{1, "b", true},
+		{1, "c", true},
+		{1, "draft", true},
+		{1, "", false},
+	}
+	current := "draft"
+	for i, s := range steps {
+		got, ok := h.browse(current, s.step)
+		if got != s.want || ok != s.ok {
+			t.Fatalf("step %d: %q %v instead of %q %v", i, got, ok, s.want, s.ok)
+		}
+		if ok {
+			current = got
+		}
+	}
+}
+
+func TestInputHistorySearch(t *testing.T) {
+	h := &inputHistory{entries: []string{"/var/log", "/tmp", "/var/lib", "/home"}, index: 4}
+	for _, want := range []string{"/var/lib", "/var/log"} {
+		current := "var"
+		if h.index < len(h.entries) {
+			current = h.entries[h.index]
+		}
+		got, ok := h.search(current)
+		if !ok || got != want {
+			t.Fatalf("%q %v instead of %q", got, ok, want)
+		}
+	}
+	if _, ok := h.search("/var/log"); ok {
+		t.Error("no older entry should match")
+	}
+	// Going back down restores the text searched
+	h.index = 3
+	if got, _ := h.browse("/home", 1); got != "var" {
+		t.Errorf("draft %q", got)
+	}
+}
+
+func TestInputHistoryPersistence(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	h, err := loadInputHistory("queries")
+	if err != nil || len(h.entries) != 0 {
+		t.Fatalf("%v, %v", h.entries, err)
+	}
+	for _, e := range []string{" /tmp ", "", "multi\nline", "/var/log", "/tmp"} {
+		if err = h.add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded, err := loadInputHistory("queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(loaded.entries, " "); got != "/var/log /tmp" || loaded.index != 2 {
+		t.Errorf("loaded %q at %d", got, loaded.index)
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	cases := []struct {
+		text string
+		pos  int
+		want int
+	}{
+		{"abc", 0, 0},
+		{"abc", 2, 2},
+		{"abc", 5, 3},
+		{"héllo", 2, 3},
+		{"héllo", 5, 6},
+		{"", 1, 0},
+	}
+	for _, c := range cases {
+		if got := byteOffset(c.text, c.pos); got != c.want {
+			t.Errorf("%q at %d: %d instead of %d", c.text, c.pos, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+// scrollStep is the number of columns of an horizontal scroll
+const scrollStep = 8
+
+// scrollDetail shifts the origin of the detail panel, within the bounds of its content
+func (app *monitorApp) scrollDetail(dx, dy int) {
+	v := app.panelDetail
+	vx, vy := v.Size()
+	ox, oy := v.Origin()
+
+	lines := v.ViewBufferLines()
+	if len(lines) == 0 {
+		lines = v.BufferLines()
+	}
+	width := 0
+	for _, line := range v.BufferLines() {
+		width = maxInt(width, utf8.RuneCountInString(line))
+	}
+
+	ox, oy = ox+dx, oy+dy
+	if oy > len(lines)-vy {
+		oy = len(lines) - vy
+	}
+	if ox > width-vx {
+		ox = width - vx
+	}
+	if oy < 0 {
+		oy = 0
+	}
+	if ox < 0 || v.Wrap {
+		ox = 0
+	}
+	if err := v.SetOrigin(ox, oy); err != nil {
+		log.Panicf("scroll detail: %v", err)
+	}
+}
+
+// keepDetailScroll resets the scroll of the detail panel unless the same item is rendered again
+func (app *monitorApp) keepDetailScroll(item MonitoredItem) {
+	key := ""
+	if item != nil {
+		key = primaryValue(item)
+	}
+	if key != app.detailKey {
+		app.detailKey = key
+		if err := app.panelDetail.SetOrigin(0, 0); err != nil {
+			log.Panicf("reset detail origin: %v", err)
+		}
+	}
+}
+
+// writeDetailText writes a text in the detail panel, with the numbers of the lines if they are enabled
+func (app *monitorApp) writeDetailText(text string) {
+	if !app.lineNumbers {
+		fmt.Fprint(app.panelDetail, text)
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		fmt.Fprintf(app.panelDetail, "%s%4d│%s %s\n", ansiBlue, i+1, ansiReset, line)
+	}
+}
+
+func (app *monitorApp) bindDetailKeys() {
+	// The list drives the table and the groups, the explorer has its own bindings
+	scrolling := func(handler func()) func(*gocui.Gui, *gocui.View) error {
+		return func(_ *gocui.Gui, _ *gocui.View) error {
+			if app.mode != modeExplore {
+				handler()
+			}
+			return nil
+		}
+	}
+	tabular := func() bool { return app.mode == modeTable || app.mode == modeGroup }
+	page := func() int {
+		_, vy := app.panelDetail.Size()
+		return vy
+	}
+	vertical := func(dy int) func() {
+		return func() {
+			if tabular() {
+				app.panelList.MoveCursor(0, dy, false)
+				app.redrawDetail()
+			} else {
+				app.scrollDetail(0, dy)
+			}
+		}
+	}
+	paging := func(nb int) func() {
+		return func() {
+			if tabular() {
+				app.shiftByNbPages(app.panelList, nb)
+				app.redrawDetail()
+			} else {
+				app.scrollDetail(0, nb*page())
+			}
+		}
+	}
+
+	bindings := []struct {
+		key     interface{}
+		handler func()
+	}{
+		{gocui.KeyArrowUp, vertical(-1)},
+		{gocui.KeyArrowDown, vertical(1)},
+		{gocui.KeyPgup, paging(-1)},
+		{gocui.KeyPgdn, paging(1)},
+		{gocui.KeyArrowLeft, func() { app.scrollDetail(-scrollStep, 0) }},
+		{gocui.KeyArrowRight, func() { app.scrollDetail(scrollStep, 0) }},
+		{gocui.KeyHome, func() { app.scrollDetail(-math.MaxInt32, -math.MaxInt32) }},
+		{gocui.KeyEnd, func() { app.scrollDetail(0, math.MaxInt32) }},
+		{'w', func() {
+			if !tabular() {
+				app.wrapDetail = !app.wrapDetail
+				app.panelDetail.Wrap = app.wrapDetail
+				app.scrollDetail(0, 0)
+			}
+		}},
+		{'l', func() {
+			app.lineNumbers = !app.lineNumbers
+			app.redrawDetail()
+		}},
+	}
+	for _, b := range bindings {
+		err := app.gui.SetKeybinding(panelNameDetail, b.key, gocui.ModNone, scrolling(b.handler))
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+
+	// The explorer moves its cursor by pages instead
+	for key, nb := range map[gocui.Key]int{gocui.KeyPgup: -1, gocui.KeyPgdn: 1} {
+		nb := nb
+		err := app.gui.SetKeybinding(panelNameDetail, key, gocui.ModNone,
+			func(_ *gocui.Gui, _ *gocui.View) error {
+				if app.mode == modeExplore {
+					app.explorerGoto(app.explorerIndex() + nb*page())
+				}
+				return nil
+			})
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshot is the content of a snapshot file: the items of a query at a given time
+type snapshot struct {
+	Query string           `json:"query"`
+	Time  time.Time        `json:"time"`
+	Items []snapshotRecord `json:"items"`
+}
+
+// snapshotRecord is an item as saved in a snapshot, with the values of all its keys
+type snapshotRecord struct {
+	Primary string            `json:"primary"`
+	Keys    []string          `json:"keys"`
+	Values  map[string]string `json:"values"`
+	Detail  string            `json:"detail"`
+}
+
+// snapshotItem is an item loaded from a snapshot
+type snapshotItem struct {
+	snapshotRecord
+}
+
+func (si *snapshotItem) GetPrimaryKey() string { return si.Primary }
+
+func (si *snapshotItem) GetKeys() []string { return si.Keys }
+
+func (si *snapshotItem) GetValue(k string) string { return si.Values[k] }
+
+func (si *snapshotItem) GetDetail() string { return si.Detail }
+
+// defaultSnapshotPath proposes a file name in the current directory, after the current time
+func defaultSnapshotPath() string {
+	return fmt.Sprintf("cui-snapshot-%s.json", time.Now().Format("20060102-150405"))
+}
+
+// saveSnapshot writes the current items in a file, without the ghosts of the removed items
+func (app *monitorApp) saveSnapshot(path string) error {
+	snap := snapshot{Query: app.query, Time: time.Now(), Items: make([]snapshotRecord, 0, len(app.items))}
+	for _, item := range app.items {
+		if _, ok := item.(*removedItem); ok {
+			continue
+		}
+		rec := snapshotRecord{
+			Primary: item.GetPrimaryKey(),
+			Keys:    item.GetKeys(),
+			Values:  make(map[string]string),
+			Detail:  item.GetDetail(),
+		}
+		for _, k := range rec.Keys {
+			rec.Values[k] = item.GetValue(k)
+		}
+		snap.Items = append(snap.Items, rec)
+	}
+
+	encoded, err := json.MarshalIndent(snap, "", " ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, encoded, 0644); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	return nil
+}
+
+// loadSnapshot reads the query and the items of a snapshot file
+func loadSnapshot(path string) (string, []MonitoredItem, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	var snap snapshot
+	if err = json.Unmarshal(encoded, &snap); err != nil {
+		return "", nil, fmt.Errorf("load snapshot %s: %w", path, err)
+	}
+	out := make([]MonitoredItem, 0, len(snap.Items))
+	for _, rec := range snap.Items {
+		if rec.Values == nil {
+			rec.Values = make(map[string]string)
+		}
+		out = append(out, &snapshotItem{rec})
+	}
+	return snap.Query, out, nil
+}
+
+// setBaseline compares the items with the ones of a snapshot file, or with the previous fetch if the path is empty.
+// A snapshot of another query is still compared, with a warning. The baseline is dropped when the query changes.
+func (app *monitorApp) setBaseline(path string) error {
+	var err error
+	if path == "" {
+		app.baseline, app.baselinePath = nil, ""
+	} else {
+		query, items, loadErr := loadSnapshot(path)
+		if loadErr != nil {
+			return loadErr
+		}
+		if query != app.query {
+			err = fmt.Errorf("baseline %s: saved for the query %q", filepath.Base(path), query)
+		}
+		app.baseline, app.baselinePath = indexItems(items), path
+	}
+	app.rediff()
+	return err
+}
+
+// reference returns the items the current ones are compared to: the baseline at the top level if any,
+// otherwise the items of the previous fetch.
+func (app *monitorApp) reference() map[string]MonitoredItem {
+	if app.baseline != nil && len(app.levels) == 0 {
+		return app.baseline
+	}
+	return app.previous
+}
+
+// referenceName describes the reference of the comparisons
+func (app *monitorApp) referenceName() string {
+	if app.baseline != nil && len(app.levels) == 0 {
+		return "the baseline " + filepath.Base(app.baselinePath)
+	}
+	return "the previous fetch"
+}
+
+// rediff replaces the ghosts and the changes of the items after a change of reference
+func (app *monitorApp) rediff() {
+	selected := app.selectedItem()
+	items := make([]MonitoredItem, 0, len(app.items))
+	for _, item := range app.items {
+		if _, ok := item.(*removedItem); !ok {
+			items = append(items, item)
+		}
+	}
+	app.items = append(items, app.diffItems()...)
+	sort.Slice(app.items, func(i, j int) bool { return app.lessItems(app.items[i], app.items[j]) })
+	app.refreshList(selected)
+}
+
+// promptSnapshot asks where to save the current items
+func (app *monitorApp) promptSnapshot() {
+	app.openPrompt("Save a snapshot to", defaultSnapshotPath(), func(path string) {
+		// A successful save keeps the error of the fetch on screen
+		if err := app.saveSnapshot(path); err != nil {
+			app.err = err
+		}
+		app.choosePanel(app.panelList)
+	})
+}
+
+// promptBaseline asks for the snapshot file to compare with, an empty path going back to the previous fetch
+func (app *monitorApp) promptBaseline() {
+	app.openPrompt("Compare with the snapshot (empty: the previous fetch)", app.baselinePath, func(path string) {
+		app.err = app.setBaseline(path)
+		app.choosePanel(app.panelList)
+	})
+}
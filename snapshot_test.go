@@ -0,0 +1,61 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snap.json")
+	app := newTestApp(testItem("a", "size", "1"), testItem("b", "size", "2"))
+	app.query = "/tmp"
+	app.items = append(app.items, &removedItem{MonitoredItem: testItem("gone"), since: "the previous fetch"})
+	if err := app.saveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	query, items, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "/tmp" {
+		t.Errorf("query %q", query)
+	}
+	if got := listedIDs(items); got != "a b" {
+		t.Fatalf("items %q, the ghosts should not be saved", got)
+	}
+	if items[1].GetValue("size") != "2" || items[1].GetDetail() != app.items[1].GetDetail() {
+		t.Errorf("item b not restored: %q", items[1].GetDetail())
+	}
+
+	// The items compared to the baseline
+	app.items = []MonitoredItem{testItem("b", "size", "3"), testItem("c")}
+	app.baseline = indexItems(items)
+	ghosts := app.diffItems()
+	if got := listedIDs(ghosts); got != "-a" {
+		t.Errorf("ghosts %q", got)
+	}
+	if app.changes["b"] != changeModified || app.changes["c"] != changeAdded {
+		t.Errorf("changes %v", app.changes)
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := loadSnapshot(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("a missing file should fail")
+	}
+	path := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadSnapshot(path); err == nil {
+		t.Error("a malformed file should fail")
+	}
+}
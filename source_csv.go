@@ -0,0 +1,257 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// CSVSource is a Monitorable that loads its items from a CSV (or TSV) file.
+// The query is the path to the file, optionally followed by space-separated options:
+//   - "delim=<c>" overrides the delimiter, with "tab", "comma", "semicolon" and "pipe" as aliases.
+//   - "key=<column>" selects the column used as the primary key.
+//   - "header" or "noheader" forces the presence or the absence of a header row.
+//   - "lazy" or "strict" tolerates or rejects the stray quotes in the fields, tolerated by default in TSV files.
+//
+// The path is everything before the first option, or may be enclosed in double quotes.
+//
+// Without explicit option, the header row is detected with a heuristic on the first line of the file.
+// When no header is present, the columns are named "col1", "col2", etc.
+// Malformed lines are skipped and reported in the error returned along with the valid items.
+type CSVSource struct {
+	// Comma is the default delimiter. Zero means ',' or a tab for files with a ".tsv" extension.
+	Comma rune
+
+	// PrimaryKey is the default primary column. Empty means the first column.
+	PrimaryKey string
+}
+
+type csvOptions struct {
+	path   string
+	comma  rune
+	key    string
+	header int // 0: detect, 1: present, -1: absent
+	lazy   int // 0: only for tabs, 1: lazy quotes, -1: strict quotes
+}
+
+type csvItem struct {
+	header  []string
+	values  []string
+	primary string
+}
+
+func (ci *csvItem) GetPrimaryKey() string { return ci.primary }
+
+func (ci *csvItem) GetKeys() []string { return ci.header }
+
+func (ci *csvItem) GetValue(k string) string {
+	for i, h := range ci.header {
+		if h == k {
+			if i < len(ci.values) {
+				return ci.values[i]
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+func (ci *csvItem) GetDetail() string {
+	var builder strings.Builder
+	w := tabwriter.NewWriter(&builder, 8, 1, 2, ' ', 0)
+	for i, h := range ci.header {
+		v := ""
+		if i < len(ci.values) {
+			v = ci.values[i]
+		}
+		fmt.Fprintf(w, "%s:\t%s\n", h, v)
+	}
+	w.Flush()
+	return builder.String()
+}
+
+// FetchAll reads the whole file designated by the query
+func (src *CSVSource) FetchAll(query string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+
+	opts, err := src.parseQuery(query)
+	if err != nil {
+		return out, err
+	}
+
+	f, err := os.Open(opts.path)
+	if err != nil {
+		return out, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil {
+		return out, err
+	} else if !info.Mode().IsRegular() {
+		return out, fmt.Errorf("%s: not a regular file", opts.path)
+	}
+
+	r := csv.NewReader(f)
+	r.Comma = opts.comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = opts.lazy > 0 || (opts.lazy == 0 && opts.comma == '\t')
+
+	var header []string
+	var errs []error
+	width := 0
+	primary := ""
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		// A malformed line is reported and skipped, a failure of the file ends the read
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, err)
+			continue
+		}
+		if err != nil {
+			return out, err
+		}
+
+		if header == nil {
+			width = len(record)
+			if opts.header > 0 || (opts.header == 0 && looksLikeHeader(record)) {
+				header = record
+				primary, err = opts.primaryKey(header)
+				if err != nil {
+					return out, err
+				}
+				continue
+			}
+			header = make([]string, width)
+			for i := range header {
+				header[i] = "col" + strconv.Itoa(i+1)
+			}
+			primary, err = opts.primaryKey(header)
+			if err != nil {
+				return out, err
+			}
+		}
+
+		if len(record) != width {
+			line, _ := r.FieldPos(0)
+			errs = append(errs, fmt.Errorf("line %d: %d fields instead of %d", line, len(record), width))
+			continue
+		}
+		out = append(out, &csvItem{header: header, values: record, primary: primary})
+	}
+
+	return out, joinErrors("malformed lines", errs)
+}
+
+func (src *CSVSource) parseQuery(query string) (csvOptions, error) {
+	opts := csvOptions{comma: src.Comma, key: src.PrimaryKey}
+
+	path, tokens, err := splitQueryPath(query, isCSVOption)
+	if err != nil {
+		return opts, err
+	}
+	if path == "" {
+		return opts, errors.New("Empty query")
+	}
+	opts.path = path
+	if opts.comma == 0 {
+		switch strings.ToLower(filepath.Ext(opts.path)) {
+		case ".tsv", ".tab":
+			opts.comma = '\t'
+		default:
+			opts.comma = ','
+		}
+	}
+
+	for _, tok := range tokens {
+		name, value, _ := strings.Cut(tok, "=")
+		switch name {
+		case "delim":
+			switch value {
+			case "tab", `\t`:
+				opts.comma = '\t'
+			case "comma":
+				opts.comma = ','
+			case "semicolon":
+				opts.comma = ';'
+			case "pipe":
+				opts.comma = '|'
+			default:
+				runes := []rune(value)
+				if len(runes) != 1 {
+					return opts, fmt.Errorf("invalid delimiter %q", value)
+				}
+				opts.comma = runes[0]
+			}
+		case "key":
+			opts.key = value
+		case "header":
+			opts.header = 1
+		case "noheader":
+			opts.header = -1
+		case "lazy":
+			opts.lazy = 1
+		case "strict":
+			opts.lazy = -1
+		default:
+			return opts, fmt.Errorf("unexpected option %q", tok)
+		}
+	}
+	return opts, nil
+}
+
+// isCSVOption tells if a token of the query is an option rather than a part of the path
+func isCSVOption(tok string) bool {
+	name, _, hasValue := strings.Cut(tok, "=")
+	switch name {
+	case "delim", "key":
+		return hasValue
+	case "header", "noheader", "lazy", "strict":
+		return !hasValue
+	}
+	return false
+}
+
+func (opts *csvOptions) primaryKey(header []string) (string, error) {
+	if len(header) == 0 {
+		return "", errors.New("no column")
+	}
+	if opts.key == "" {
+		return header[0], nil
+	}
+	for _, h := range header {
+		if h == opts.key {
+			return h, nil
+		}
+	}
+	return "", fmt.Errorf("no such column %q", opts.key)
+}
+
+// looksLikeHeader tells if a record is a plausible header: non-empty, distinct, non-numeric fields
+func looksLikeHeader(record []string) bool {
+	seen := make(map[string]bool)
+	for _, field := range record {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			return false
+		}
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return false
+		}
+		seen[field] = true
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCSVParseQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  csvOptions
+		fails bool
+	}{
+		{query: "/tmp/a.csv", want: csvOptions{path: "/tmp/a.csv", comma: ','}},
+		{query: "/tmp/a.tsv", want: csvOptions{path: "/tmp/a.tsv", comma: '\t'}},
+		{query: "/tmp/a.csv delim=; key=serial", want: csvOptions{path: "/tmp/a.csv", comma: ';', key: "serial"}},
+		{query: "/tmp/a.csv delim=pipe noheader", want: csvOptions{path: "/tmp/a.csv", comma: '|', header: -1}},
+		{query: "/tmp/a.csv header lazy", want: csvOptions{path: "/tmp/a.csv", comma: ',', header: 1, lazy: 1}},
+		{query: "/tmp/a.tsv strict", want: csvOptions{path: "/tmp/a.tsv", comma: '\t', lazy: -1}},
+		{query: "/tmp/my  export.csv key=id", want: csvOptions{path: "/tmp/my  export.csv", comma: ',', key: "id"}},
+		{query: `"/tmp/my header.csv" header`, want: csvOptions{path: "/tmp/my header.csv", comma: ',', header: 1}},
+		{query: "/tmp/my header.csv", want: csvOptions{path: "/tmp/my header.csv", comma: ','}},
+		{query: "", fails: true},
+		{query: `"/tmp/a.csv`, fails: true},
+		{query: "/tmp/a.csv delim=ab", fails: true},
+		{query: "/tmp/a.csv key=id bogus", fails: true},
+	}
+	src := CSVSource{}
+	for _, c := range cases {
+		got, err := src.parseQuery(c.query)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %+v", c.query, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.query, err)
+		} else if got != c.want {
+			t.Errorf("%q: got %+v, expected %+v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestCSVFetchAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my export.csv")
+	content := "serial;size\nA1;10\nB\"2;20\nC3\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := CSVSource{}
+
+	// Strict quotes by default with semicolons: the line with a stray quote is malformed
+	items, err := src.FetchAll(path + " delim=; key=size")
+	if err == nil || len(items) != 1 {
+		t.Fatalf("strict: got %d items and %v", len(items), err)
+	}
+
+	items, err = src.FetchAll(path + " delim=; key=size lazy")
+	if err == nil {
+		t.Fatal("lazy: the short line should be reported")
+	}
+	if len(items) != 2 {
+		t.Fatalf("lazy: got %d items instead of 2", len(items))
+	}
+	if pk := items[1].GetPrimaryKey(); pk != "size" || items[1].GetValue("serial") != `B"2` {
+		t.Errorf("lazy: unexpected item %s=%q", pk, items[1].GetValue("serial"))
+	}
+
+	if _, err = src.FetchAll(path + " key=nope"); err == nil {
+		t.Error("an unknown key column should fail")
+	}
+}
+
+func TestCSVFetchAllNotAFile(t *testing.T) {
+	src, dir := CSVSource{}, t.TempDir()
+	done := make(chan error, 1)
+	go func() {
+		_, err := src.FetchAll(dir)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("a directory should fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the read of a directory never ends")
+	}
+}
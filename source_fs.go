@@ -0,0 +1,474 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FSSource is a Monitorable listing files.
+// The query is an absolute path, quoted or not, optionally followed by space-separated options:
+//   - "depth=<n>" bounds the recursion, 1 only lists the entries of the directory and 0 means no limit.
+//   - "recursive" is a synonym of "depth=0".
+//   - "follow" follows the symbolic links, to stat their target and to descend into linked directories.
+//
+// The path may contain glob patterns, with "**" matching any number of directories, e.g. "/var/log/**/*.gz".
+// A plain directory is listed without recursion by default, while a glob is walked as deep as required.
+// The unreadable entries are reported in a PartialError along with the items that could be read.
+type FSSource struct {
+	// MaxDepth is the default recursion depth, when positive. It applies to the plain directories and to the
+	// globs containing "**", and is overridden by the "depth" option.
+	MaxDepth int
+
+	// FollowSymlinks enables the "follow" option by default
+	FollowSymlinks bool
+}
+
+type fsOptions struct {
+	root     string
+	pattern  []string // The glob split in path segments, relative to root
+	maxDepth int      // <= 0 for no limit
+	follow   bool
+}
+
+type fsWalk struct {
+	opts      fsOptions
+	out       []MonitoredItem
+	errs      []error
+	ancestors []os.FileInfo
+}
+
+type fsItem struct {
+	abs    string
+	follow bool
+
+	Path   string      `json:"path"`
+	Type   string      `json:"type"`
+	Size   int64       `json:"size"`
+	Mode   fs.FileMode `json:"mode"`
+	Target string      `json:"target,omitempty"`
+	Owner  string      `json:"owner,omitempty"`
+	Group  string      `json:"group,omitempty"`
+	UID    uint64      `json:"uid"`
+	GID    uint64      `json:"gid"`
+	Inode  uint64      `json:"inode"`
+	NLink  uint64      `json:"nlink"`
+	ATime  time.Time   `json:"atime"`
+	MTime  time.Time   `json:"mtime"`
+	CTime  time.Time   `json:"ctime"`
+}
+
+// fsStat is the part of the file metadata that is only available through a platform specific stat()
+type fsStat struct {
+	uid, gid     uint64
+	inode, nlink uint64
+	atime, ctime time.Time
+}
+
+var fsItemKeys = []string{"path", "type", "size", "mode", "target", "owner", "group", "uid", "gid", "inode", "nlink",
+	"atime", "mtime", "ctime"}
+
+func (fi *fsItem) GetPrimaryKey() string { return "path" }
+
+func (fi *fsItem) GetKeys() []string { return fsItemKeys }
+
+func (fi *fsItem) GetValue(k string) string {
+	switch k {
+	case "path":
+		return fi.Path
+	case "type":
+		return fi.Type
+	case "size":
+		return strconv.FormatInt(fi.Size, 10)
+	case "mode":
+		return fi.Mode.String()
+	case "target":
+		return fi.Target
+	case "owner":
+		return fi.Owner
+	case "group":
+		return fi.Group
+	case "uid":
+		return strconv.FormatUint(fi.UID, 10)
+	case "gid":
+		return strconv.FormatUint(fi.GID, 10)
+	case "inode":
+		return strconv.FormatUint(fi.Inode, 10)
+	case "nlink":
+		return strconv.FormatUint(fi.NLink, 10)
+	case "atime":
+		return fi.ATime.Format(time.RFC3339)
+	case "mtime":
+		return fi.MTime.Format(time.RFC3339)
+	case "ctime":
+		return fi.CTime.Format(time.RFC3339)
+	default:
+		return "-"
+	}
+}
+
+// HasChildren tells if the item is a directory
+func (fi *fsItem) HasChildren() bool { return fi.Mode.IsDir() }
+
+// FetchChildren lists the entries of the directory
+func (fi *fsItem) FetchChildren(_ context.Context) ([]MonitoredItem, error) {
+	w := fsWalk{opts: fsOptions{root: fi.abs, maxDepth: 1, follow: fi.follow}}
+	w.walk(fi.abs, fi.Path, 1)
+	return w.out, joinErrors("unreadable entries", w.errs)
+}
+
+func (fi *fsItem) GetDetail() string {
+	var builder strings.Builder
+	encoder := json.NewEncoder(&builder)
+	encoder.SetIndent("", " ")
+	encoder.Encode(*fi)
+	return builder.String()
+}
+
+// GetStructuredDetail exposes the metadata of the file, rendered by the detail panel
+func (fi *fsItem) GetStructuredDetail() interface{} { return *fi }
+
+// FetchAll walks the file tree designated by the query
+func (src *FSSource) FetchAll(query string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+
+	opts, err := src.parseQuery(query)
+	if err != nil {
+		return out, err
+	}
+
+	rootInfo, err := os.Stat(opts.root)
+	if err != nil {
+		return out, err
+	}
+	if !rootInfo.IsDir() {
+		return out, fmt.Errorf("%s: not a directory", opts.root)
+	}
+
+	w := fsWalk{opts: opts, ancestors: []os.FileInfo{rootInfo}}
+	w.walk(opts.root, "", 1)
+	return w.out, joinErrors("unreadable entries", w.errs)
+}
+
+func (src *FSSource) parseQuery(query string) (fsOptions, error) {
+	opts := fsOptions{follow: src.FollowSymlinks}
+
+	path, tokens, err := splitQueryPath(query, isFSOption)
+	if err != nil {
+		return opts, err
+	}
+	if path == "" {
+		return opts, errors.New("Empty query")
+	}
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return opts, errors.New("Relative query path")
+	}
+
+	// Split the static prefix of the path from the glob
+	segments := strings.Split(strings.TrimPrefix(path, string(filepath.Separator)), string(filepath.Separator))
+	root := string(filepath.Separator)
+	for i, segment := range segments {
+		if isGlob(segment) {
+			opts.pattern = segments[i:]
+			break
+		}
+		root = filepath.Join(root, segment)
+	}
+	opts.root = root
+
+	// A glob without "**" has a fixed depth, that the default depth can't cut short
+	switch {
+	case opts.pattern != nil && !strings.Contains(path, "**"):
+		opts.maxDepth = len(opts.pattern)
+	case src.MaxDepth > 0:
+		opts.maxDepth = src.MaxDepth
+	case opts.pattern == nil:
+		opts.maxDepth = 1
+	default:
+		opts.maxDepth = 0
+	}
+
+	for _, tok := range tokens {
+		name, value, _ := strings.Cut(tok, "=")
+		switch name {
+		case "depth":
+			depth, err := strconv.Atoi(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid depth %q", value)
+			}
+			opts.maxDepth = depth
+		case "recursive":
+			opts.maxDepth = 0
+		case "follow":
+			opts.follow = true
+		default:
+			return opts, fmt.Errorf("unexpected option %q", tok)
+		}
+	}
+	return opts, nil
+}
+
+// isFSOption tells if a token of the query is an option rather than a part of the path
+func isFSOption(tok string) bool {
+	name, _, hasValue := strings.Cut(tok, "=")
+	switch name {
+	case "depth":
+		return hasValue
+	case "recursive", "follow":
+		return !hasValue
+	}
+	return false
+}
+
+func (w *fsWalk) walk(dir, rel string, depth int) {
+	// ReadDir returns the entries read before an error
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.errs = append(w.errs, err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		relPath := filepath.Join(rel, entry.Name())
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			w.errs = append(w.errs, err)
+			continue
+		}
+		item := newFSItem(relPath, info)
+		item.abs, item.follow = path, w.opts.follow
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			item.Target, err = os.Readlink(path)
+			if err != nil {
+				w.errs = append(w.errs, err)
+			}
+			if w.opts.follow {
+				if targetInfo, err := os.Stat(path); err != nil {
+					w.errs = append(w.errs, err)
+				} else {
+					info = targetInfo
+					target := item.Target
+					item = newFSItem(relPath, info)
+					item.abs, item.follow, item.Target = path, true, target
+				}
+			}
+		}
+
+		if w.opts.pattern == nil || matchGlob(w.opts.pattern, strings.Split(relPath, string(filepath.Separator))) {
+			w.out = append(w.out, item)
+		}
+
+		if !info.IsDir() || (w.opts.maxDepth > 0 && depth >= w.opts.maxDepth) || w.isAncestor(info) {
+			continue
+		}
+		// Skip the directories that the literal segments of the glob exclude
+		if w.opts.pattern != nil && !matchGlobPrefix(w.opts.pattern, strings.Split(relPath, string(filepath.Separator))) {
+			continue
+		}
+		w.ancestors = append(w.ancestors, info)
+		w.walk(path, relPath, depth+1)
+		w.ancestors = w.ancestors[:len(w.ancestors)-1]
+	}
+}
+
+// isAncestor detects the loops introduced by the symbolic links
+func (w *fsWalk) isAncestor(info os.FileInfo) bool {
+	for _, a := range w.ancestors {
+		if os.SameFile(a, info) {
+			return true
+		}
+	}
+	return false
+}
+
+func newFSItem(path string, info os.FileInfo) *fsItem {
+	item := &fsItem{
+		Path:  path,
+		Type:  fileType(info.Mode()),
+		Size:  info.Size(),
+		Mode:  info.Mode(),
+		MTime: info.ModTime(),
+		ATime: info.ModTime(),
+		CTime: info.ModTime(),
+	}
+	if st, ok := statOf(info); ok {
+		item.UID, item.GID = st.uid, st.gid
+		item.Inode, item.NLink = st.inode, st.nlink
+		item.ATime, item.CTime = st.atime, st.ctime
+		item.Owner = lookupUser(st.uid)
+		item.Group = lookupGroup(st.gid)
+	}
+	return item
+}
+
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeCharDevice != 0:
+		return "chardev"
+	case mode&fs.ModeDevice != 0:
+		return "blockdev"
+	default:
+		return "other"
+	}
+}
+
+// isGlob tells if a path segment holds any pattern meta-character
+func isGlob(segment string) bool { return strings.ContainsAny(segment, "*?[") }
+
+// matchGlob matches path segments against pattern segments, where "**" matches any number of segments
+func matchGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], segments[1:])
+}
+
+// matchGlobPrefix tells if the path segments of a directory may lead to a match of the pattern segments
+func matchGlobPrefix(pattern, segments []string) bool {
+	switch {
+	case len(segments) == 0:
+		return true
+	case len(pattern) == 0:
+		return false
+	case pattern[0] == "**":
+		return true
+	}
+	if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlobPrefix(pattern[1:], segments[1:])
+}
+
+var (
+	namesLock  sync.Mutex
+	userNames  = make(map[uint64]string)
+	groupNames = make(map[uint64]string)
+)
+
+func lookupUser(uid uint64) string {
+	namesLock.Lock()
+	defer namesLock.Unlock()
+	name, ok := userNames[uid]
+	if !ok {
+		id := strconv.FormatUint(uid, 10)
+		if u, err := user.LookupId(id); err == nil {
+			name = u.Username
+		} else {
+			name = id
+		}
+		userNames[uid] = name
+	}
+	return name
+}
+
+func lookupGroup(gid uint64) string {
+	namesLock.Lock()
+	defer namesLock.Unlock()
+	name, ok := groupNames[gid]
+	if !ok {
+		id := strconv.FormatUint(gid, 10)
+		if g, err := user.LookupGroupId(id); err == nil {
+			name = g.Name
+		} else {
+			name = id
+		}
+		groupNames[gid] = name
+	}
+	return name
+}
+
+// fsOptionNames are the options completed after the path of the query
+var fsOptionNames = []string{"depth=", "recursive", "follow"}
+
+// fsMaxCompletions bounds the number of candidates of a completion
+const fsMaxCompletions = 200
+
+// CompleteQuery completes the path of the query with the directories it designates, then the options
+func (src *FSSource) CompleteQuery(prefix string) ([]string, error) {
+	// After the path, complete the last option
+	if i := strings.LastIndexAny(prefix, " \t"); i >= 0 {
+		head, last := prefix[:i+1], prefix[i+1:]
+		out := make([]string, 0)
+		for _, name := range fsOptionNames {
+			if strings.HasPrefix(name, last) && !strings.Contains(head, " "+name) {
+				out = append(out, head+name)
+			}
+		}
+		return out, nil
+	}
+
+	if prefix == "" {
+		return []string{string(filepath.Separator)}, nil
+	}
+	cut := strings.LastIndex(prefix, string(filepath.Separator))
+	if cut < 0 {
+		return nil, nil
+	}
+	dir, base := prefix[:cut+1], prefix[cut+1:]
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if isDir {
+			out = append(out, dir+name+string(filepath.Separator))
+		}
+		if len(out) >= fsMaxCompletions {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build linux
+
+package cui
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func statOf(info os.FileInfo) (fsStat, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fsStat{}, false
+	}
+	return fsStat{
+		uid:   uint64(st.Uid),
+		gid:   uint64(st.Gid),
+		inode: uint64(st.Ino),
+		nlink: uint64(st.Nlink),
+		atime: time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec)),
+		ctime: time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec)),
+	}, true
+}
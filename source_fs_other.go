@@ -0,0 +1,12 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !linux
+
+package cui
+
+import "os"
+
+func statOf(_ os.FileInfo) (fsStat, bool) { return fsStat{}, false }
@@ -0,0 +1,161 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFSParseQueryDepth(t *testing.T) {
+	cases := []struct {
+		query    string
+		maxDepth int // of the source
+		want     int
+	}{
+		{query: "/var/log", want: 1},
+		{query: "/var/log", maxDepth: 3, want: 3},
+		{query: "/var/log depth=2", maxDepth: 3, want: 2},
+		{query: "/var/log recursive", want: 0},
+		{query: "/a/*/b/*.log", want: 3},
+		{query: "/a/*/b/*.log", maxDepth: 1, want: 3},
+		{query: "/a/*/b/*.log depth=1", want: 1},
+		{query: "/var/log/**/*.gz", want: 0},
+		{query: "/var/log/**/*.gz", maxDepth: 2, want: 2},
+		{query: "/var/log/**/*.gz depth=4", maxDepth: 2, want: 4},
+	}
+	for _, c := range cases {
+		src := FSSource{MaxDepth: c.maxDepth}
+		opts, err := src.parseQuery(c.query)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.query, err)
+		} else if opts.maxDepth != c.want {
+			t.Errorf("%q with MaxDepth=%d: depth %d instead of %d", c.query, c.maxDepth, opts.maxDepth, c.want)
+		}
+	}
+
+	for _, query := range []string{"", "var/log", "/var/log depth=x", `"/var/log`} {
+		if _, err := (&FSSource{}).parseQuery(query); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestFSParseQueryPattern(t *testing.T) {
+	opts, err := (&FSSource{}).parseQuery("/var/log/**/*.gz follow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.root != "/var/log" || !reflect.DeepEqual(opts.pattern, []string{"**", "*.gz"}) || !opts.follow {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
+
+func TestFSParseQueryPath(t *testing.T) {
+	cases := []struct {
+		query, root string
+		depth       int
+	}{
+		{query: "/var/log bogus", root: "/var/log bogus", depth: 1},
+		{query: "/home/me/My Documents  depth=2", root: "/home/me/My Documents", depth: 2},
+		{query: "/home/me/depth recursive", root: "/home/me/depth", depth: 0},
+		{query: `"/home/me/x depth=2" follow`, root: "/home/me/x depth=2", depth: 1},
+	}
+	for _, c := range cases {
+		opts, err := (&FSSource{}).parseQuery(c.query)
+		if err != nil {
+			t.Errorf("%q: %v", c.query, err)
+		} else if opts.root != c.root || opts.maxDepth != c.depth {
+			t.Errorf("%q: root %q and depth %d", c.query, opts.root, opts.maxDepth)
+		}
+	}
+}
+
+func TestMatchGlobPrefix(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*/b/*.log", "a", true},
+		{"*/b/*.log", "a/b", true},
+		{"*/b/*.log", "a/c", false},
+		{"*/b/*.log", "a/b/c", false},
+		{"log/**/*.gz", "log/x/y", true},
+		{"log/**/*.gz", "tmp", false},
+		{"*.gz", "d", false},
+	}
+	for _, c := range cases {
+		if got := matchGlobPrefix(strings.Split(c.pattern, "/"), strings.Split(c.path, "/")); got != c.want {
+			t.Errorf("%s under %s: %v", c.pattern, c.path, got)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.log", "a.log", true},
+		{"*.log", "d/a.log", false},
+		{"**/*.log", "a.log", true},
+		{"**/*.log", "d/e/a.log", true},
+		{"*/b/*.log", "a/b/c.log", true},
+		{"*/b/*.log", "a/c/c.log", false},
+		{"**", "a/b", true},
+	}
+	for _, c := range cases {
+		if got := matchGlob(strings.Split(c.pattern, "/"), strings.Split(c.path, "/")); got != c.want {
+			t.Errorf("%s on %s: %v", c.pattern, c.path, got)
+		}
+	}
+}
+
+func TestFSFetchAll(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"a/b/x.log", "a/y.log", "c/z.txt", "my logs/w.log", "top.log"} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(path), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		query    string
+		maxDepth int
+		want     string
+	}{
+		{query: root, want: "a c my logs top.log"},
+		{query: root + " depth=2", want: "a a/b a/y.log c c/z.txt my logs my logs/w.log top.log"},
+		{query: root + "/**/*.log", want: "a/b/x.log a/y.log my logs/w.log top.log"},
+		{query: root + "/**/*.log", maxDepth: 2, want: "a/y.log my logs/w.log top.log"},
+		{query: root + "/*/*/*.log", maxDepth: 1, want: "a/b/x.log"},
+		{query: root + "/my logs", want: "w.log"},
+		{query: `"` + root + `/my logs/*.log" depth=1`, want: "w.log"},
+		{query: root + "/my logs/**/*.log recursive", want: "w.log"},
+	}
+	for _, c := range cases {
+		items, err := (&FSSource{MaxDepth: c.maxDepth}).FetchAll(c.query)
+		if err != nil {
+			t.Errorf("%q: %v", c.query, err)
+			continue
+		}
+		paths := make([]string, 0, len(items))
+		for _, item := range items {
+			paths = append(paths, item.GetValue("path"))
+		}
+		sort.Strings(paths)
+		if got := strings.Join(paths, " "); got != c.want {
+			t.Errorf("%q with MaxDepth=%d: got %q, expected %q", c.query, c.maxDepth, got, c.want)
+		}
+	}
+}
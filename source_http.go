@@ -0,0 +1,101 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// HTTPSource is a Monitorable fetching a JSON document over HTTP.
+// The query is the URL, optionally followed by a selector (e.g. ".data.items") that locates the array
+// of objects in the document. Each object becomes an item whose keys are the fields of the object.
+type HTTPSource struct {
+	// Client performs the requests. Nil means http.DefaultClient.
+	Client *http.Client
+
+	// Timeout bounds each fetch. Zero means 10 seconds.
+	Timeout time.Duration
+
+	// Headers are added to each request, e.g. for an "Authorization" or an "Accept" header.
+	Headers http.Header
+
+	// Selector is the default selector, used when the query carries none.
+	Selector string
+
+	// PrimaryKey is the field used as the primary key. Empty means the first present field among
+	// "id", "key", "name", "path", or else the first field in alphabetical order.
+	PrimaryKey string
+}
+
+// FetchAll performs a GET request on the URL in the query
+func (src *HTTPSource) FetchAll(query string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+
+	tokens := strings.Fields(query)
+	if len(tokens) == 0 {
+		return out, errors.New("Empty query")
+	}
+	url, selector := tokens[0], src.Selector
+	if len(tokens) > 1 {
+		selector = tokens[1]
+	}
+
+	timeout := src.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return out, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, values := range src.Headers {
+		req.Header.Del(k)
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	client := src.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rep, err := client.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer rep.Body.Close()
+
+	if rep.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(rep.Body, 256))
+		return out, fmt.Errorf("HTTP %s: %s", rep.Status, strings.TrimSpace(string(body)))
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(rep.Body)
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return out, fmt.Errorf("Format error: %w", err)
+	}
+
+	selected, err := selectJSON(doc, selector)
+	if err != nil {
+		return out, err
+	}
+	return jsonItems(selected, src.PrimaryKey)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newJSONServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "denied", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPSourceSelector(t *testing.T) {
+	srv := newJSONServer(t, `{"data": {"items": [{"id": "a", "size": 1}, {"id": "b", "size": 2}]},
+		"groups": [{"members": [{"name": "x"}]}, {"members": [{"name": "y"}, {"name": "z"}]}]}`)
+	src := HTTPSource{Headers: http.Header{"Authorization": {"Bearer secret"}}}
+
+	items, err := src.FetchAll(srv.URL + " .data.items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].GetPrimaryKey() != "id" || items[1].GetValue("size") != "2" {
+		t.Fatalf("unexpected items %v", items)
+	}
+
+	src.Selector = ".groups[*].members"
+	items, err = src.FetchAll(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[2].GetValue("name") != "z" {
+		t.Fatalf("unexpected projected items %v", items)
+	}
+}
+
+func TestHTTPSourceHeaders(t *testing.T) {
+	srv := newJSONServer(t, `[{"id": "a"}]`)
+	src := HTTPSource{}
+	if _, err := src.FetchAll(srv.URL); err == nil {
+		t.Fatal("a request without the header should be denied")
+	}
+	src.Headers = http.Header{"Authorization": {"Bearer secret"}}
+	if items, err := src.FetchAll(srv.URL); err != nil || len(items) != 1 {
+		t.Fatalf("got %d items and %v", len(items), err)
+	}
+}
+
+func TestHTTPSourceTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	src := HTTPSource{Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err := src.FetchAll(srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the timeout took %v", elapsed)
+	}
+}
+
+func TestHTTPSourceResults(t *testing.T) {
+	cases := []struct {
+		body  string
+		count int
+		fails bool
+	}{
+		{body: `{"id": "alone"}`, count: 1},
+		{body: `[{"id": "a"}, 3, {"id": "b"}]`, count: 2, fails: true},
+		{body: `"text"`, fails: true},
+		{body: `42`, fails: true},
+		{body: `[`, fails: true},
+	}
+	src := HTTPSource{Headers: http.Header{"Authorization": {"Bearer secret"}}}
+	for _, c := range cases {
+		srv := newJSONServer(t, c.body)
+		items, err := src.FetchAll(srv.URL)
+		if (err != nil) != c.fails || len(items) != c.count {
+			t.Errorf("%s: got %d items and %v", c.body, len(items), err)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProcRoot = "/proc"
+
+	// userHZ is the unit of the times in /proc/<pid>/stat, fixed to 100 by the kernel ABI on all the
+	// common architectures.
+	userHZ = 100
+)
+
+// ProcSource is a Monitorable listing the processes of a Linux host, as exposed in procfs.
+// The query is a space-separated list of optional filters:
+//   - "user=<name>" only keeps the processes running as the given user (name or uid).
+//   - "name=<glob>", or a bare glob, only keeps the processes whose command name matches.
+//
+// An empty query lists all the processes.
+type ProcSource struct {
+	// Root is the mount point of procfs. Empty means "/proc".
+	Root string
+}
+
+type procFilter struct {
+	user string
+	name *regexp.Regexp // A glob whose "*" also matches the "/" of the names like "kworker/0:1"
+}
+
+type procItem struct {
+	root string
+
+	pid     int
+	ppid    int
+	uid     string
+	user    string
+	state   string
+	name    string
+	cmdline string
+	rss     uint64
+	vsz     uint64
+	threads int
+	cpu     time.Duration
+	start   time.Time
+}
+
+var procItemKeys = []string{"pid", "ppid", "user", "state", "name", "cmdline", "rss", "vsz", "threads", "cputime",
+	"start"}
+
+func (pi *procItem) GetPrimaryKey() string { return "pid" }
+
+func (pi *procItem) GetKeys() []string { return procItemKeys }
+
+func (pi *procItem) GetValue(k string) string {
+	switch k {
+	case "pid":
+		return strconv.Itoa(pi.pid)
+	case "ppid":
+		return strconv.Itoa(pi.ppid)
+	case "user":
+		return pi.user
+	case "state":
+		return pi.state
+	case "name":
+		return pi.name
+	case "cmdline":
+		return pi.cmdline
+	case "rss":
+		return strconv.FormatUint(pi.rss, 10)
+	case "vsz":
+		return strconv.FormatUint(pi.vsz, 10)
+	case "threads":
+		return strconv.Itoa(pi.threads)
+	case "cputime":
+		return strconv.FormatFloat(pi.cpu.Seconds(), 'f', 2, 64)
+	case "start":
+		if pi.start.IsZero() {
+			return ""
+		}
+		return pi.start.Format(time.RFC3339)
+	default:
+		return "-"
+	}
+}
+
+// GetDetail reads the status, the limits and the count of open file descriptors of the process.
+// They are read at display time because they are too expensive to collect for all the processes.
+func (pi *procItem) GetDetail() string {
+	var b strings.Builder
+	dir := filepath.Join(pi.root, strconv.Itoa(pi.pid))
+
+	fmt.Fprintf(&b, "cmdline: %s\n", pi.cmdline)
+	if entries, err := os.ReadDir(filepath.Join(dir, "fd")); err != nil {
+		fmt.Fprintf(&b, "fds: %v\n", err)
+	} else {
+		fmt.Fprintf(&b, "fds: %d\n", len(entries))
+	}
+
+	for _, name := range []string{"status", "limits"} {
+		fmt.Fprintf(&b, "\n# %s\n", name)
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err != nil {
+			fmt.Fprintf(&b, "%v\n", err)
+		} else {
+			b.Write(content)
+		}
+	}
+	return b.String()
+}
+
+// CounterKeys tells the CPU time is a counter, its rate is the CPU usage of the process
+func (src *ProcSource) CounterKeys() []string { return []string{"cputime"} }
+
+// FetchAll scans the process directories in procfs
+func (src *ProcSource) FetchAll(query string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+
+	filter, err := parseProcQuery(query)
+	if err != nil {
+		return out, err
+	}
+
+	root := src.Root
+	if root == "" {
+		root = defaultProcRoot
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return out, err
+	}
+	bootTime, err := readBootTime(root)
+	if err != nil {
+		return out, err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		item, err := readProcess(root, pid, bootTime)
+		if err != nil {
+			// A process may exit between the listing and the read
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("pid %d: %w", pid, err))
+			}
+			continue
+		}
+		if filter.accept(item) {
+			out = append(out, item)
+		}
+	}
+	return out, joinErrors("unreadable processes", errs)
+}
+
+func parseProcQuery(query string) (procFilter, error) {
+	var filter procFilter
+	pattern := ""
+	for _, tok := range strings.Fields(query) {
+		name, value, found := strings.Cut(tok, "=")
+		switch {
+		case !found:
+			pattern = tok
+		case name == "user":
+			filter.user = value
+		case name == "name":
+			pattern = value
+		default:
+			return filter, fmt.Errorf("unexpected option %q", tok)
+		}
+	}
+	if pattern != "" {
+		re, err := globRegexp(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+		filter.name = re
+	}
+	return filter, nil
+}
+
+func (f *procFilter) accept(pi *procItem) bool {
+	if f.user != "" && f.user != pi.user && f.user != pi.uid {
+		return false
+	}
+	return f.name == nil || f.name.MatchString(pi.name)
+}
+
+func readProcess(root string, pid int, bootTime time.Time) (*procItem, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	item := &procItem{root: root, pid: pid}
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	if err = item.parseStat(stat, bootTime); err != nil {
+		return nil, err
+	}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	item.uid, item.user = parseStatusUser(status)
+
+	// The command line is empty for the kernel threads, and unreadable for some processes
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		item.cmdline = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	}
+	if item.cmdline == "" {
+		item.cmdline = "[" + item.name + "]"
+	}
+	return item, nil
+}
+
+// parseStat decodes the content of /proc/<pid>/stat, see proc(5)
+func (pi *procItem) parseStat(stat []byte, bootTime time.Time) error {
+	// The command name is enclosed in parenthesis and may contain spaces and parenthesis
+	open, closing := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return errors.New("malformed stat")
+	}
+	pi.name = string(stat[open+1 : closing])
+
+	// fields[0] is the 3rd field of the file: the state
+	fields := strings.Fields(string(stat[closing+1:]))
+	if len(fields) < 22 {
+		return errors.New("truncated stat")
+	}
+	atoi := func(i int) uint64 {
+		u, _ := strconv.ParseUint(fields[i], 10, 64)
+		return u
+	}
+
+	pi.state = fields[0]
+	pi.ppid = int(atoi(1))
+	pi.cpu = time.Duration(atoi(11)+atoi(12)) * time.Second / userHZ
+	pi.threads = int(atoi(17))
+	if !bootTime.IsZero() {
+		pi.start = bootTime.Add(time.Duration(atoi(19)) * time.Second / userHZ)
+	}
+	pi.vsz = atoi(20)
+	pi.rss = atoi(21) * uint64(os.Getpagesize())
+	return nil
+}
+
+// parseStatusUser extracts the id and the name of the real user from the content of /proc/<pid>/status
+func parseStatusUser(status []byte) (string, string) {
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(line[4:])
+		if len(fields) == 0 {
+			return "", ""
+		}
+		uid, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return fields[0], fields[0]
+		}
+		return fields[0], lookupUser(uid)
+	}
+	return "", ""
+}
+
+// readBootTime extracts the boot time from /proc/stat, the start times of the processes are relative to it.
+func readBootTime(root string) (time.Time, error) {
+	content, err := os.ReadFile(filepath.Join(root, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			sec, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("malformed btime: %w", err)
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// procFixture is a fake procfs with 3 processes: init (pid 1, root), a process whose command name contains
+// spaces and parenthesis (pid 42, uid 1000) and a kernel thread without command line (pid 77, root).
+const procFixture = "testdata/proc"
+
+func fetchProcs(t *testing.T, query string) map[int]*procItem {
+	items, err := (&ProcSource{Root: procFixture}).FetchAll(query)
+	if err != nil {
+		t.Fatalf("%q: %v", query, err)
+	}
+	out := make(map[int]*procItem)
+	for _, item := range items {
+		pi := item.(*procItem)
+		out[pi.pid] = pi
+	}
+	return out
+}
+
+func pidsOf(procs map[int]*procItem) string {
+	pids := make([]int, 0, len(procs))
+	for pid := range procs {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	s := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		s = append(s, strconv.Itoa(pid))
+	}
+	return strings.Join(s, " ")
+}
+
+func TestProcFilters(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: "1 42 77"},
+		{query: "user=1000", want: "42"},
+		{query: "user=" + lookupUser(0), want: "1 77"},
+		{query: "user=nobody-here", want: ""},
+		{query: "init", want: "1"},
+		{query: "name=kworker*", want: "77"},
+		{query: "name=my*proc", want: "42"},
+		{query: "user=0 k*", want: "77"},
+	}
+	for _, c := range cases {
+		if got := pidsOf(fetchProcs(t, c.query)); got != c.want {
+			t.Errorf("%q: got pids %q, expected %q", c.query, got, c.want)
+		}
+	}
+
+	for _, query := range []string{"bogus=1", "name=[a"} {
+		if _, err := (&ProcSource{Root: procFixture}).FetchAll(query); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestProcParsing(t *testing.T) {
+	procs := fetchProcs(t, "")
+	boot := time.Unix(1700000000, 0)
+
+	p := procs[42]
+	if p.name != "my (weird) proc" || p.state != "R" || p.ppid != 1 || p.threads != 3 {
+		t.Errorf("pid 42: unexpected stat %+v", p)
+	}
+	if p.cpu != 2*time.Second || !p.start.Equal(boot.Add(10*time.Second)) {
+		t.Errorf("pid 42: cpu %v start %v", p.cpu, p.start)
+	}
+	if p.vsz != 123456789 || p.rss != 250*uint64(os.Getpagesize()) {
+		t.Errorf("pid 42: vsz %d rss %d", p.vsz, p.rss)
+	}
+	if p.uid != "1000" || p.cmdline != "python3 -m http.server" {
+		t.Errorf("pid 42: uid %q cmdline %q", p.uid, p.cmdline)
+	}
+	if p.GetValue("cputime") != "2.00" {
+		t.Errorf("pid 42: cputime %q", p.GetValue("cputime"))
+	}
+
+	if k := procs[77]; k.cmdline != "[kworker/0:1]" || k.uid != "0" || k.user != lookupUser(0) {
+		t.Errorf("pid 77: unexpected kernel thread %+v", k)
+	}
+	if procs[1].cmdline != "/sbin/init splash" {
+		t.Errorf("pid 1: cmdline %q", procs[1].cmdline)
+	}
+}
+
+func TestProcParseStatErrors(t *testing.T) {
+	for _, stat := range []string{"", "12 noparens S 1", "12 (short) S 1 2 3"} {
+		var pi procItem
+		if err := pi.parseStat([]byte(stat), time.Time{}); err == nil {
+			t.Errorf("%q: expected an error", stat)
+		}
+	}
+}
+
+func TestProcDetail(t *testing.T) {
+	procs := fetchProcs(t, "")
+
+	detail := procs[42].GetDetail()
+	for _, want := range []string{"cmdline: python3 -m http.server\n", "fds: 3\n", "# limits\n",
+		"Max open files            65536", "# status\n", "Name:\tmy (weird) proc"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("pid 42: the detail lacks %q:\n%s", want, detail)
+		}
+	}
+	if detail := procs[1].GetDetail(); !strings.Contains(detail, "fds: 2\n") {
+		t.Errorf("pid 1: wrong count of fds:\n%s", detail)
+	}
+
+	// The missing files are reported in the detail
+	detail = procs[77].GetDetail()
+	if strings.Contains(detail, "fds: 0") || !strings.Contains(detail, "fds: open") {
+		t.Errorf("pid 77: the missing fd directory isn't reported:\n%s", detail)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRPCTimeout = 10 * time.Second
+
+// NetRpcBaseRequest is the common part of the requests sent by a RPCSource.
+// Timeout tells the server how long the client is ready to wait, Fields carries optional parameters.
+type NetRpcBaseRequest struct {
+	Timeout time.Duration
+	Fields  map[string]interface{}
+}
+
+// RPCPayloadReply is the default reply of a RPCSource: a JSON array of objects in an opaque payload.
+type RPCPayloadReply struct {
+	Payload []byte
+}
+
+// RPCSource is a Monitorable calling a net/rpc method over HTTP.
+// The query is the address of the server, as "host" or "host:port".
+// The connection is kept open between two fetches as long as the address doesn't change.
+type RPCSource struct {
+	// Method is the name of the remote method, e.g. "Sys.TitanObjects"
+	Method string
+
+	// Port is appended to the query when it lacks one.
+	Port string
+
+	// Timeout bounds the connection and each call, it is also forwarded to the server in the request.
+	// Zero means 10 seconds.
+	Timeout time.Duration
+
+	// NewRequest builds the argument of the call. Nil means the NetRpcBaseRequest itself is sent.
+	NewRequest func(query string, base NetRpcBaseRequest) interface{}
+
+	// NewReply allocates the reply of the call. Nil means a *RPCPayloadReply.
+	NewReply func() interface{}
+
+	// Decode turns a reply into items. Nil means DecodeJSONPayload.
+	Decode func(reply interface{}) ([]MonitoredItem, error)
+
+	lock   sync.Mutex
+	addr   string
+	client *rpc.Client
+}
+
+// DecodeJSONPayload is the default decoder of a RPCSource, it expects a *RPCPayloadReply whose payload is a JSON
+// array of objects.
+func DecodeJSONPayload(reply interface{}) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+	rep, ok := reply.(*RPCPayloadReply)
+	if !ok {
+		return out, fmt.Errorf("unexpected reply type %T", reply)
+	}
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(rep.Payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return out, fmt.Errorf("Format error: %w", err)
+	}
+	return jsonItems(doc, "")
+}
+
+// FetchAll calls the remote method on the server designated by the query
+func (src *RPCSource) FetchAll(query string) ([]MonitoredItem, error) {
+	var out []MonitoredItem
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return out, errors.New("Empty query")
+	}
+	if src.Method == "" {
+		return out, errors.New("No RPC method configured")
+	}
+
+	addr := query
+	if _, _, err := net.SplitHostPort(addr); err != nil && src.Port != "" {
+		addr = net.JoinHostPort(addr, src.Port)
+	}
+
+	base := NetRpcBaseRequest{Timeout: src.timeout()}
+	var request interface{} = base
+	if src.NewRequest != nil {
+		request = src.NewRequest(query, base)
+	}
+	var reply interface{} = &RPCPayloadReply{}
+	if src.NewReply != nil {
+		reply = src.NewReply()
+	}
+
+	if err := src.call(addr, request, reply); err != nil {
+		return out, err
+	}
+
+	if src.Decode != nil {
+		return src.Decode(reply)
+	}
+	return DecodeJSONPayload(reply)
+}
+
+// Close releases the connection kept open between two fetches
+func (src *RPCSource) Close() error {
+	src.lock.Lock()
+	defer src.lock.Unlock()
+	return src.reset()
+}
+
+func (src *RPCSource) timeout() time.Duration {
+	if src.Timeout <= 0 {
+		return defaultRPCTimeout
+	}
+	return src.Timeout
+}
+
+func (src *RPCSource) call(addr string, request, reply interface{}) error {
+	src.lock.Lock()
+	defer src.lock.Unlock()
+
+	if src.client != nil && src.addr != addr {
+		src.reset()
+	}
+
+	// A connection reused from a previous fetch may have been closed by the server meanwhile: retry once on a
+	// fresh connection, but only if the client knew it was closed and didn't send the request. A request that
+	// may have reached the server isn't sent twice, the remote methods needn't be idempotent.
+	reused := src.client != nil
+	err := src.callOnce(addr, request, reply)
+	if err != nil && reused && errors.Is(err, rpc.ErrShutdown) {
+		err = src.callOnce(addr, request, reply)
+	}
+	return err
+}
+
+func (src *RPCSource) callOnce(addr string, request, reply interface{}) error {
+	if src.client == nil {
+		client, err := dialHTTPTimeout(addr, src.timeout())
+		if err != nil {
+			return fmt.Errorf("can't connect to %s: %w", addr, err)
+		}
+		src.client, src.addr = client, addr
+	}
+
+	call := src.client.Go(src.Method, request, reply, make(chan *rpc.Call, 1))
+	timer := time.NewTimer(src.timeout())
+	defer timer.Stop()
+
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			if isConnectionError(call.Error) {
+				src.reset()
+			}
+			return fmt.Errorf("%s failed: %w", src.Method, call.Error)
+		}
+		return nil
+	case <-timer.C:
+		// The pending call is abandoned along with the connection
+		src.reset()
+		return fmt.Errorf("%s failed: timeout after %v", src.Method, src.timeout())
+	}
+}
+
+func (src *RPCSource) reset() error {
+	if src.client == nil {
+		return nil
+	}
+	err := src.client.Close()
+	src.client, src.addr = nil, ""
+	return err
+}
+
+func isConnectionError(err error) bool {
+	if errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// dialHTTPTimeout does what rpc.DialHTTP does, but within a bounded delay
+func dialHTTPTimeout(addr string, timeout time.Duration) (*rpc.Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	io.WriteString(conn, "CONNECT "+rpc.DefaultRPCPath+" HTTP/1.0\n\n")
+	rep, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	if err == nil && rep.Status != "200 Connected to Go RPC" {
+		err = errors.New("unexpected HTTP response: " + rep.Status)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackingListener keeps the accepted connections, to close them as a server dropping its clients
+type trackingListener struct {
+	net.Listener
+	lock  sync.Mutex
+	conns []net.Conn
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.lock.Lock()
+		l.conns = append(l.conns, conn)
+		l.lock.Unlock()
+	}
+	return conn, err
+}
+
+func (l *trackingListener) dropAll() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+type testService struct {
+	calls    int32
+	listener *trackingListener
+}
+
+func (s *testService) List(_ NetRpcBaseRequest, reply *RPCPayloadReply) error {
+	atomic.AddInt32(&s.calls, 1)
+	reply.Payload = []byte(`[{"id": "a"}, {"id": "b"}]`)
+	return nil
+}
+
+// Drop loses the reply after the call reached the server
+func (s *testService) Drop(_ NetRpcBaseRequest, _ *RPCPayloadReply) error {
+	atomic.AddInt32(&s.calls, 1)
+	s.listener.dropAll()
+	return nil
+}
+
+func startRPCServer(t *testing.T) (*testService, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &trackingListener{Listener: l}
+	service := &testService{listener: listener}
+	server := rpc.NewServer()
+	if err = server.RegisterName("Test", service); err != nil {
+		t.Fatal(err)
+	}
+	go http.Serve(listener, server)
+	t.Cleanup(func() { l.Close() })
+	return service, l.Addr().String()
+}
+
+func TestRPCSourceReconnects(t *testing.T) {
+	service, addr := startRPCServer(t)
+	src := RPCSource{Method: "Test.List", Timeout: 5 * time.Second}
+	defer src.Close()
+
+	items, err := src.FetchAll(addr)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("got %d items and %v", len(items), err)
+	}
+
+	// The server closes the idle connection, the client notices it and the next fetch reconnects
+	service.listener.dropAll()
+	time.Sleep(100 * time.Millisecond)
+	items, err = src.FetchAll(addr)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("after reconnection: got %d items and %v", len(items), err)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 2 {
+		t.Fatalf("%d calls instead of 2", calls)
+	}
+}
+
+func TestRPCSourceDoesNotRepeatCalls(t *testing.T) {
+	service, addr := startRPCServer(t)
+	src := RPCSource{Method: "Test.List", Timeout: 5 * time.Second}
+	defer src.Close()
+	if _, err := src.FetchAll(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reply of a call that reached the server is lost: the call fails instead of being sent again
+	src.Method = "Test.Drop"
+	if _, err := src.FetchAll(addr); err == nil {
+		t.Fatal("the lost reply should fail the fetch")
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 2 {
+		t.Fatalf("%d calls instead of 2", calls)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	statsTopN          = 10
+	statsBuckets       = 10
+	statsHistogramBars = 40
+)
+
+// valueCount is a value of a key and the number of items holding it
+type valueCount struct {
+	value string
+	count int
+}
+
+// keyStats describes the distribution of the values of a key across the items
+type keyStats struct {
+	key      string
+	items    int
+	present  int
+	distinct int
+	top      []valueCount
+	numbers  []float64 // Sorted
+}
+
+// computeStats gathers the statistics of a key over the given items, ignoring the ghosts of removed items
+func computeStats(items []MonitoredItem, k string) keyStats {
+	stats := keyStats{key: k}
+	counts := make(map[string]int)
+	for _, item := range items {
+		if _, ok := item.(*removedItem); ok {
+			continue
+		}
+		stats.items++
+		if !hasKey(item, k) {
+			continue
+		}
+		stats.present++
+		value := item.GetValue(k)
+		counts[value]++
+		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			stats.numbers = append(stats.numbers, v)
+		}
+	}
+
+	stats.distinct = len(counts)
+	for value, count := range counts {
+		stats.top = append(stats.top, valueCount{value, count})
+	}
+	sort.Slice(stats.top, func(i, j int) bool {
+		if stats.top[i].count != stats.top[j].count {
+			return stats.top[i].count > stats.top[j].count
+		}
+		return stats.top[i].value < stats.top[j].value
+	})
+	if len(stats.top) > statsTopN {
+		stats.top = stats.top[:statsTopN]
+	}
+	sort.Float64s(stats.numbers)
+	return stats
+}
+
+func hasKey(item MonitoredItem, k string) bool {
+	for _, key := range item.GetKeys() {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// percentile returns the nearest-rank percentile of the sorted numbers
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func (stats *keyStats) write(w io.Writer) {
+	fmt.Fprintf(w, "%sStatistics of %s%s\n\n", ansiBold, stats.key, ansiReset)
+	if stats.items == 0 {
+		fmt.Fprintln(w, "No item")
+		return
+	}
+	fmt.Fprintf(w, "present:  %d/%d (%.1f%%)\n", stats.present, stats.items,
+		100*float64(stats.present)/float64(stats.items))
+	fmt.Fprintf(w, "distinct: %d\n", stats.distinct)
+
+	if len(stats.top) > 0 {
+		fmt.Fprintf(w, "\n%sTop values%s\n", ansiBold, ansiReset)
+		rows := make([][]tableCell, 0, len(stats.top))
+		for _, vc := range stats.top {
+			rows = append(rows, []tableCell{{text: strconv.Itoa(vc.count)}, {text: vc.value}})
+		}
+		writeTable(w, rows)
+		fmt.Fprintln(w)
+	}
+
+	if len(stats.numbers) == 0 {
+		return
+	}
+	sum := 0.0
+	for _, v := range stats.numbers {
+		sum += v
+	}
+	fmt.Fprintf(w, "\n%sNumeric values%s (%d)\n", ansiBold, ansiReset, len(stats.numbers))
+	fmt.Fprintf(w, "min=%s max=%s mean=%s\n", formatFloat(stats.numbers[0]),
+		formatFloat(stats.numbers[len(stats.numbers)-1]), formatFloat(sum/float64(len(stats.numbers))))
+	fmt.Fprintf(w, "p50=%s p90=%s p99=%s\n", formatFloat(percentile(stats.numbers, 50)),
+		formatFloat(percentile(stats.numbers, 90)), formatFloat(percentile(stats.numbers, 99)))
+	stats.writeHistogram(w)
+}
+
+func (stats *keyStats) writeHistogram(w io.Writer) {
+	min, max := stats.numbers[0], stats.numbers[len(stats.numbers)-1]
+	buckets := make([]int, statsBuckets)
+	for _, v := range stats.numbers {
+		i := 0
+		if max > min {
+			i = int((v - min) / (max - min) * statsBuckets)
+		}
+		if i >= statsBuckets {
+			i = statsBuckets - 1
+		} else if i < 0 {
+			i = 0
+		}
+		buckets[i]++
+	}
+	highest := 0
+	for _, count := range buckets {
+		if count > highest {
+			highest = count
+		}
+	}
+
+	fmt.Fprintf(w, "\n%sHistogram%s\n", ansiBold, ansiReset)
+	rows := make([][]tableCell, 0, statsBuckets)
+	width := (max - min) / statsBuckets
+	for i, count := range buckets {
+		if max == min && i > 0 {
+			break
+		}
+		bar := strings.Repeat("█", count*statsHistogramBars/highest)
+		rows = append(rows, []tableCell{
+			{text: "[" + formatFloat(min+float64(i)*width) + ", " + formatFloat(min+float64(i+1)*width) + ")"},
+			{text: strconv.Itoa(count)},
+			{text: bar, style: ansiCyan},
+		})
+	}
+	writeTable(w, rows)
+	fmt.Fprintln(w)
+}
+
+// redrawStats displays the statistics of the current key over the displayed items
+func (app *monitorApp) redrawStats() {
+	app.panelDetail.Clear()
+	k := app.itemKeyNameOrPrimary()
+	if k == "" {
+		return
+	}
+	var b strings.Builder
+	stats := computeStats(app.rows, k)
+	stats.write(&b)
+	app.writeDetailText(b.String())
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	items := []MonitoredItem{
+		testItem("a", "v", "3"),
+		testItem("b", "v", "1"),
+		testItem("c", "v", "inf"),
+		testItem("d", "v", "-Inf"),
+		testItem("e", "v", "NaN"),
+		testItem("f", "v", "x"),
+		testItem("g", "v", "3"),
+		testItem("h"),
+		&removedItem{testItem("i", "v", "100"), "the previous fetch"},
+	}
+	stats := computeStats(items, "v")
+	if stats.items != 8 || stats.present != 7 || stats.distinct != 6 {
+		t.Errorf("items %d present %d distinct %d", stats.items, stats.present, stats.distinct)
+	}
+	if !reflect.DeepEqual(stats.numbers, []float64{1, 3, 3}) {
+		t.Errorf("numbers %v", stats.numbers)
+	}
+	if stats.top[0] != (valueCount{"3", 2}) {
+		t.Errorf("top value %+v", stats.top[0])
+	}
+
+	// Rendering the histogram doesn't panic on the extreme values
+	var b strings.Builder
+	stats.write(&b)
+	if !strings.Contains(b.String(), "p50=3") {
+		t.Errorf("unexpected rendering:\n%s", b.String())
+	}
+}
+
+func TestHistogramBounds(t *testing.T) {
+	cases := [][]float64{
+		{5},
+		{5, 5, 5},
+		{-1e308, 1e308},
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+	}
+	for _, numbers := range cases {
+		var b strings.Builder
+		stats := keyStats{key: "v", items: len(numbers), present: len(numbers), numbers: numbers}
+		stats.writeHistogram(&b)
+		if !strings.Contains(b.String(), "Histogram") {
+			t.Errorf("%v: no histogram", numbers)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, want := range map[float64]float64{0: 1, 50: 5, 90: 9, 99: 10, 100: 10} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%v: %v instead of %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jroimartin/gocui"
+)
+
+// StreamingMonitorable is an optional interface of a Monitorable that delivers its items progressively.
+// When a source implements it, FetchStream is called instead of FetchAll and the list is rendered as the
+// batches of items arrive. The stream can be stopped early with Alt-s.
+type StreamingMonitorable interface {
+	Monitorable
+
+	// FetchStream sends batches of items on the channel until the source is exhausted or the context is
+	// cancelled. It must not send anything after it returned, and it must not close the channel.
+	// The items already sent are kept when an error is returned.
+	FetchStream(ctx context.Context, query string, out chan<- []MonitoredItem) error
+}
+
+// itemStream buffers the batches received from a StreamingMonitorable until the GUI loop merges them
+type itemStream struct {
+	cancel context.CancelFunc
+
+	lock    sync.Mutex
+	pending []MonitoredItem
+	done    bool
+	err     error
+}
+
+func (app *monitorApp) startStream(source StreamingMonitorable) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &itemStream{cancel: cancel}
+	app.stream = s
+	app.streamStopped = false
+
+	batches := make(chan []MonitoredItem, 16)
+	errc := make(chan error, 1)
+	query := app.query
+
+	go func() {
+		errc <- source.FetchStream(ctx, query, batches)
+		close(batches)
+	}()
+
+	// The callbacks passed to gocui.Gui.Update may run in any order, hence the pending items are buffered
+	// and each callback merges everything received so far.
+	go func() {
+		for batch := range batches {
+			s.lock.Lock()
+			s.pending = append(s.pending, batch...)
+			s.lock.Unlock()
+			app.gui.Update(func(_ *gocui.Gui) error { app.flushStream(s); return nil })
+		}
+		err := <-errc
+		s.lock.Lock()
+		s.done, s.err = true, err
+		s.lock.Unlock()
+		app.gui.Update(func(_ *gocui.Gui) error { app.flushStream(s); return nil })
+	}()
+}
+
+// flushStream merges the pending items of the stream, it runs in the GUI loop
+func (app *monitorApp) flushStream(s *itemStream) {
+	if app.stream != s {
+		// A stale callback of a former stream
+		return
+	}
+
+	s.lock.Lock()
+	pending, done, err := s.pending, s.done, s.err
+	s.pending = nil
+	s.lock.Unlock()
+
+	if len(pending) == 0 && !done {
+		return
+	}
+	selected := app.selectedItem()
+	app.mergeItems(pending)
+	if done {
+		s.cancel()
+		app.stream = nil
+		if errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		app.err = err
+		app.mergeItems(app.diffItems())
+		app.recordHistory()
+		app.evaluateAlerts()
+		if watchable, ok := app.source.(WatchableMonitorable); ok && err == nil {
+			app.startWatch(watchable)
+		}
+	}
+	app.refreshList(selected)
+}
+
+// cancelStream interrupts the stream in progress and drops the items not merged yet
+func (app *monitorApp) cancelStream() {
+	if app.stream != nil {
+		app.stream.cancel()
+		app.stream = nil
+	}
+}
+
+// stopStream interrupts the stream in progress, the items already received are kept
+func (app *monitorApp) stopStream() {
+	if app.stream == nil {
+		return
+	}
+	s := app.stream
+	app.cancelStream()
+	app.streamStopped = true
+
+	// Keep what has been received so far
+	s.lock.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.lock.Unlock()
+	if len(pending) > 0 {
+		selected := app.selectedItem()
+		app.mergeItems(pending)
+		app.refreshList(selected)
+	} else {
+		app.panelList.Title = app.listTitle()
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	tableMinWidth = 8
+	tablePadding  = 2
+
+	// The bounds of the width of the list panel, and the step of its resizing
+	listMinWidth  = 8
+	listMaxWidth  = 120
+	listWidthStep = 4
+)
+
+// tableCell is a value of the table mode, with its optional ANSI style
+type tableCell struct {
+	text  string
+	style string
+}
+
+// writeTable aligns the cells in columns, like a tabwriter.Writer with a minimal width of 8 and a padding of 2.
+// Unlike a tabwriter.Writer, it ignores the ANSI styles when computing the widths.
+func writeTable(w io.Writer, rows [][]tableCell) {
+	widths := make([]int, 0)
+	for _, row := range rows {
+		// The last cell of a row isn't aligned
+		for i, cell := range row[:maxInt(len(row)-1, 0)] {
+			width := utf8.RuneCountInString(cell.text) + tablePadding
+			if width < tableMinWidth {
+				width = tableMinWidth
+			}
+			if i >= len(widths) {
+				widths = append(widths, width)
+			} else if width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	var b strings.Builder
+	for r, row := range rows {
+		if r > 0 {
+			b.WriteString("\n")
+		}
+		for i, cell := range row {
+			if cell.style != "" {
+				b.WriteString(cell.style)
+				b.WriteString(cell.text)
+				b.WriteString(ansiReset)
+			} else {
+				b.WriteString(cell.text)
+			}
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell.text)))
+			}
+		}
+	}
+	io.WriteString(w, b.String())
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// fitWidth truncates or pads a text to a fixed width, a width of 0 leaving it unchanged
+func fitWidth(text string, width int) string {
+	length := utf8.RuneCountInString(text)
+	switch {
+	case width <= 0 || length == width:
+		return text
+	case length < width:
+		return text + strings.Repeat(" ", width-length)
+	default:
+		return string([]rune(text)[:width-1]) + "…"
+	}
+}
+
+// parseWidths reads the widths of the columns, like "path=30 size=10"
+func parseWidths(s string) (map[string]int, error) {
+	widths := make(map[string]int)
+	for _, tok := range strings.Fields(s) {
+		key, value, ok := strings.Cut(tok, "=")
+		width, err := strconv.Atoi(value)
+		if !ok || key == "" || err != nil || width < 0 {
+			return nil, fmt.Errorf("invalid width %q, expected key=N", tok)
+		}
+		widths[key] = width
+	}
+	return widths, nil
+}
+
+// formatWidths writes the widths of the columns in the form read by parseWidths
+func formatWidths(widths map[string]int) string {
+	keys := make([]string, 0, len(widths))
+	for k := range widths {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tokens := make([]string, 0, len(keys))
+	for _, k := range keys {
+		tokens = append(tokens, fmt.Sprintf("%s=%d", k, widths[k]))
+	}
+	return strings.Join(tokens, " ")
+}
+
+// promptWidths asks for the fixed widths of the columns of the table
+func (app *monitorApp) promptWidths() {
+	app.openPrompt("Widths of the columns: key=N ...", formatWidths(app.widths), func(text string) {
+		widths, err := parseWidths(text)
+		if err != nil {
+			app.err = err
+			return
+		}
+		app.widths = widths
+		app.redrawTable()
+	})
+}
+
+// resizeList changes the width of the list panel, within bounds
+func (app *monitorApp) resizeList(delta int) {
+	maxX, _ := app.gui.Size()
+	app.listWidth = clampListWidth(app.listWidth+delta, maxX)
+}
+
+// clampListWidth bounds the width of the list panel, leaving room for the detail panel in a screen of the given
+// width. The lower bound wins on a tiny screen.
+func clampListWidth(width, maxX int) int {
+	if width > maxX-listMinWidth {
+		width = maxX - listMinWidth
+	}
+	if width > listMaxWidth {
+		width = listMaxWidth
+	}
+	if width < listMinWidth {
+		width = listMinWidth
+	}
+	return width
+}
+
+func (app *monitorApp) bindWidthKeys() {
+	bindings := []struct {
+		key     rune
+		handler func()
+	}{
+		{'<', func() { app.resizeList(-listWidthStep) }},
+		{'>', func() { app.resizeList(listWidthStep) }},
+		{'=', app.promptWidths},
+	}
+	for _, b := range bindings {
+		handler := b.handler
+		err := app.gui.SetKeybinding("", b.key, gocui.ModAlt,
+			app.whenNoPopup(func(_ *gocui.Gui, _ *gocui.View) error {
+				handler()
+				return nil
+			}))
+		if err != nil {
+			log.Panicln(err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// treeSearchDepth bounds the depth of the children fetched to search the tree
+	treeSearchDepth = 4
+	// treeSearchBudget bounds the time spent fetching children when a search is submitted
+	treeSearchBudget = 2 * time.Second
+)
+
+// treeNode is a visible line of the list in tree mode
+type treeNode struct {
+	item   MonitoredItem
+	path   string // The primary key values from the root, identifying the node across refreshes, see nodePath
+	depth  int
+	parent bool // Whether the item has children
+	open   bool // Whether the children are displayed
+	last   bool // Whether the node is the last visible one among its siblings
+	prefix string
+}
+
+// toggleTree switches the list between the flat and the tree modes
+func (app *monitorApp) toggleTree() {
+	selected := app.selectedItem()
+	app.treeMode = !app.treeMode
+	app.refreshList(selected)
+}
+
+// treeChildren returns the children of a node, sorted, fetching them only once per query
+func (app *monitorApp) treeChildren(node *treeNode) []MonitoredItem {
+	if children, ok := app.treeCache[node.path]; ok {
+		return children
+	}
+	children, err := fetchChildren(node.item)
+	if err != nil {
+		app.err = err
+	}
+	sort.SliceStable(children, func(i, j int) bool { return app.lessItems(children[i], children[j]) })
+	if app.treeCache == nil {
+		app.treeCache = make(map[string][]MonitoredItem)
+	}
+	app.treeCache[node.path] = children
+	app.addPossibleKeys(children)
+	return children
+}
+
+// childrenKnown tells if the children of a node are available without a fetch
+func (app *monitorApp) childrenKnown(node *treeNode) bool {
+	if _, ok := app.treeCache[node.path]; ok {
+		return true
+	}
+	_, ok := node.item.(ParentItem)
+	return ok
+}
+
+// nodePath identifies a node by the primary key values from the root. The siblings sharing the same value, or
+// lacking one, are told apart by their rank among them.
+func nodePath(parentPath, value string, seen map[string]int) string {
+	rank := seen[value]
+	seen[value] = rank + 1
+	if rank == 0 && value != "" {
+		return parentPath + "/" + value
+	}
+	return parentPath + "/" + value + "#" + strconv.Itoa(rank)
+}
+
+// searchChildren tells if the children of a node are searched: the known ones are, the others are fetched
+// within the depth and the time allowed for the search submitted last.
+func (app *monitorApp) searchChildren(node *treeNode) bool {
+	if app.childrenKnown(node) {
+		return true
+	}
+	if node.depth < treeSearchDepth && time.Now().Before(app.searchUntil) {
+		return true
+	}
+	app.searchPartial = true
+	return false
+}
+
+// flattenTree lists the visible nodes under the given items. During a search, a node is visible if it matches
+// or if one of its searched descendants does, then it is open.
+func (app *monitorApp) flattenTree(items []MonitoredItem, parentPath string, depth int) ([]*treeNode, bool) {
+	type entry struct {
+		node *treeNode
+		sub  []*treeNode
+	}
+	entries := make([]entry, 0, len(items))
+	searching := app.search != ""
+	seen := make(map[string]int)
+	for _, item := range items {
+		if app.rowFilter != nil && depth == 0 && !app.rowFilter.accept(item) {
+			continue
+		}
+		node := &treeNode{item: item, path: nodePath(parentPath, primaryValue(item), seen), depth: depth}
+		node.parent = isParent(item)
+
+		var sub []*treeNode
+		subMatched := false
+		expanded := app.expanded[node.path]
+		if node.parent && (expanded || (searching && app.searchChildren(node))) {
+			sub, subMatched = app.flattenTree(app.treeChildren(node), node.path, depth+1)
+		}
+		if searching && !subMatched && !app.matchesSearch(item) {
+			continue
+		}
+		node.open = expanded || (searching && subMatched)
+		if !node.open {
+			sub = nil
+		}
+		entries = append(entries, entry{node, sub})
+	}
+
+	out := make([]*treeNode, 0, len(entries))
+	for i, e := range entries {
+		e.node.last = i == len(entries)-1
+		out = append(out, e.node)
+		out = append(out, e.sub...)
+	}
+	return out, len(entries) > 0
+}
+
+// updateTreeRows computes the visible nodes and their indentation guides
+func (app *monitorApp) updateTreeRows() {
+	app.searchPartial = false
+	nodes, _ := app.flattenTree(app.items, "", 0)
+	lastAt := make([]bool, 0)
+	app.rows = make([]MonitoredItem, 0, len(nodes))
+	for _, node := range nodes {
+		lastAt = append(lastAt[:node.depth], node.last)
+
+		var b strings.Builder
+		for i := 1; i < node.depth; i++ {
+			if lastAt[i] {
+				b.WriteString("  ")
+			} else {
+				b.WriteString("│ ")
+			}
+		}
+		if node.depth > 0 {
+			if node.last {
+				b.WriteString("└")
+			} else {
+				b.WriteString("├")
+			}
+		}
+		switch {
+		case !node.parent:
+			b.WriteString("─ ")
+		case node.open:
+			b.WriteString("▾ ")
+		default:
+			b.WriteString("▸ ")
+		}
+		node.prefix = b.String()
+		app.rows = append(app.rows, node.item)
+	}
+	app.treeRows = nodes
+}
+
+// selectedNode returns the node under the cursor in tree mode
+func (app *monitorApp) selectedNode() *treeNode {
+	index := app.selectedIndex()
+	if !app.treeMode || index < 0 || index >= len(app.treeRows) {
+		return nil
+	}
+	return app.treeRows[index]
+}
+
+// expandNode opens or closes the node under the cursor
+func (app *monitorApp) expandNode(open bool) {
+	node := app.selectedNode()
+	if node == nil {
+		return
+	}
+	if !node.parent {
+		open = false
+	}
+	if !open && !node.open && node.depth > 0 {
+		// Closing a closed node goes to its parent
+		index := app.selectedIndex()
+		for index > 0 && app.treeRows[index].depth >= node.depth {
+			index--
+		}
+		app.selectIndex(index)
+		app.redrawDetail()
+		return
+	}
+	if app.expanded == nil {
+		app.expanded = make(map[string]bool)
+	}
+	if open {
+		app.expanded[node.path] = true
+	} else {
+		delete(app.expanded, node.path)
+	}
+	app.refreshList(node.item)
+}
+
+// matchesSearch tells if the displayed value of an item contains the searched text, ignoring the case
+func (app *monitorApp) matchesSearch(item MonitoredItem) bool {
+	return strings.Contains(strings.ToLower(app.itemKeyValue(item)), strings.ToLower(app.search))
+}
+
+// promptSearch asks for the text searched in the list
+func (app *monitorApp) promptSearch() {
+	app.openPrompt("Search", app.search, func(text string) {
+		app.search = text
+		// Only the submission of a search fetches children, not the redraws that follow
+		app.searchUntil = time.Now().Add(treeSearchBudget)
+		app.refreshList(app.selectedItem())
+		app.searchUntil = time.Time{}
+		app.choosePanel(app.panelList)
+	})
+}
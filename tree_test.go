@@ -0,0 +1,94 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lazyItem is a LazyParentItem counting the fetches of its children
+type lazyItem struct {
+	MonitoredItem
+	children []MonitoredItem
+	fetches  *int
+}
+
+func (li *lazyItem) HasChildren() bool { return len(li.children) > 0 }
+
+func (li *lazyItem) FetchChildren(_ context.Context) ([]MonitoredItem, error) {
+	*li.fetches++
+	return li.children, nil
+}
+
+// lazyChain builds a chain of nested lazy items, the deepest one being a leaf
+func lazyChain(fetches *int, ids ...string) MonitoredItem {
+	var item MonitoredItem = testItem(ids[len(ids)-1])
+	for i := len(ids) - 2; i >= 0; i-- {
+		item = &lazyItem{MonitoredItem: testItem(ids[i]), children: []MonitoredItem{item}, fetches: fetches}
+	}
+	return item
+}
+
+func treePaths(app *monitorApp) string {
+	paths := make([]string, 0, len(app.treeRows))
+	for _, node := range app.treeRows {
+		paths = append(paths, node.path)
+	}
+	return strings.Join(paths, " ")
+}
+
+func TestTreeSearchFetchesChildren(t *testing.T) {
+	fetches := 0
+	app := newTestApp(lazyChain(&fetches, "a", "b", "c", "needle"), lazyChain(&fetches, "x", "y"))
+	app.treeMode = true
+	app.search = "needle"
+
+	// The redraws don't fetch anything, the search is partial
+	app.updateRows()
+	if len(app.rows) != 0 || fetches != 0 || !app.searchPartial {
+		t.Fatalf("rows %d, fetches %d, partial %v", len(app.rows), fetches, app.searchPartial)
+	}
+
+	// The submission of the search fetches the children, the redraws then use them
+	app.searchUntil = time.Now().Add(treeSearchBudget)
+	app.updateRows()
+	app.searchUntil = time.Time{}
+	if got := treePaths(app); got != "/a /a/b /a/b/c /a/b/c/needle" || app.searchPartial {
+		t.Fatalf("paths %q, partial %v", got, app.searchPartial)
+	}
+	count := fetches
+	app.updateRows()
+	if fetches != count || len(app.rows) != 4 {
+		t.Fatalf("the redraw fetched again or lost rows: %d fetches, %d rows", fetches, len(app.rows))
+	}
+}
+
+func TestTreeSearchDepth(t *testing.T) {
+	fetches := 0
+	app := newTestApp(lazyChain(&fetches, "1", "2", "3", "4", "5", "6", "needle"))
+	app.treeMode = true
+	app.search = "needle"
+	app.searchUntil = time.Now().Add(treeSearchBudget)
+	app.updateRows()
+	if len(app.rows) != 0 || fetches != treeSearchDepth || !app.searchPartial {
+		t.Fatalf("rows %d, fetches %d, partial %v", len(app.rows), fetches, app.searchPartial)
+	}
+}
+
+func TestTreePathsOfSiblings(t *testing.T) {
+	parent := &lazyItem{MonitoredItem: testItem("p"), fetches: new(int),
+		children: []MonitoredItem{testItem("a"), testItem("a"), testItem(""), testItem("")}}
+	app := newTestApp(parent)
+	app.treeMode = true
+	app.expanded = map[string]bool{"/p": true}
+	app.updateRows()
+	if got := treePaths(app); got != "/p /p/#0 /p/#1 /p/a /p/a#1" {
+		t.Fatalf("paths %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// EventType tells how an item changed
+type EventType int
+
+const (
+	EventAdded EventType = iota
+	EventModified
+	EventDeleted
+)
+
+// ItemEvent notifies a change of an item of a WatchableMonitorable
+type ItemEvent struct {
+	Type EventType
+
+	// Key is the value of the primary key of the item. It may be left empty when Item is set.
+	Key string
+
+	// Item is the new state of the item. It is ignored for EventDeleted.
+	Item MonitoredItem
+}
+
+// WatchableMonitorable is an optional interface of a Monitorable that notifies the changes of its items, instead of
+// being polled. Once the items have been fetched, Watch is called and the events are applied to the list in place.
+type WatchableMonitorable interface {
+	Monitorable
+
+	// Watch sends the changes of the items matching the query until the context is cancelled.
+	// It must not send anything after it returned, and it must not close the channel.
+	Watch(ctx context.Context, query string, events chan<- ItemEvent) error
+}
+
+// watchDeletion is the origin of the ghosts of the items deleted by a watch event
+const watchDeletion = "the deletion event"
+
+// itemWatch buffers the events received from a WatchableMonitorable until the GUI loop applies them
+type itemWatch struct {
+	cancel context.CancelFunc
+
+	lock    sync.Mutex
+	pending []ItemEvent
+	done    bool
+	err     error
+}
+
+func (app *monitorApp) startWatch(source WatchableMonitorable) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &itemWatch{cancel: cancel}
+	app.watch = w
+
+	events := make(chan ItemEvent, 64)
+	errc := make(chan error, 1)
+	query := app.query
+
+	go func() {
+		errc <- source.Watch(ctx, query, events)
+		close(events)
+	}()
+
+	go func() {
+		for evt := range events {
+			w.lock.Lock()
+			w.pending = append(w.pending, evt)
+			w.lock.Unlock()
+			app.gui.Update(func(_ *gocui.Gui) error { app.flushWatch(w); return nil })
+		}
+		err := <-errc
+		w.lock.Lock()
+		w.done, w.err = true, err
+		w.lock.Unlock()
+		app.gui.Update(func(_ *gocui.Gui) error { app.flushWatch(w); return nil })
+	}()
+}
+
+func (app *monitorApp) cancelWatch() {
+	if app.watch != nil {
+		app.watch.cancel()
+		app.watch = nil
+	}
+}
+
+// flushWatch applies the pending events of the watch, it runs in the GUI loop
+func (app *monitorApp) flushWatch(w *itemWatch) {
+	if app.watch != w {
+		return
+	}
+
+	w.lock.Lock()
+	pending, done, err := w.pending, w.done, w.err
+	w.pending = nil
+	w.lock.Unlock()
+
+	selected := app.selectedItem()
+	for _, evt := range pending {
+		if replaced := app.applyEvent(evt); replaced != nil && replaced == selected {
+			selected = evt.Item
+		}
+	}
+	if done {
+		app.watch = nil
+		// A watch ending normally leaves the errors of the source on screen
+		if err != nil && !errors.Is(err, context.Canceled) {
+			app.err = err
+		}
+	}
+	if len(pending) > 0 || done {
+		app.refreshList(selected)
+	}
+}
+
+// applyEvent updates the list with a single event, it returns the item removed or replaced, if any.
+func (app *monitorApp) applyEvent(evt ItemEvent) MonitoredItem {
+	key := evt.Key
+	if key == "" && evt.Item != nil {
+		key = primaryValue(evt.Item)
+	}
+
+	var former MonitoredItem
+	for i, item := range app.items {
+		if primaryValue(item) == key {
+			former = item
+			app.items = append(app.items[:i], app.items[i+1:]...)
+			break
+		}
+	}
+	_, wasGhost := former.(*removedItem)
+
+	switch {
+	case evt.Type == EventDeleted:
+		app.evaluateItemAlerts(key, nil)
+		if former != nil && !wasGhost {
+			// The ghost of the item stays in the list while its row is highlighted
+			app.insertItem(&removedItem{former, watchDeletion})
+			app.markRow(key, ansiRed)
+		}
+	case evt.Item == nil:
+		// Nothing to insert
+	default:
+		app.insertItem(evt.Item)
+		app.recordItem(evt.Item, time.Now(), app.history)
+		app.evaluateItemAlerts(key, evt.Item)
+		if former == nil || wasGhost {
+			app.markRow(key, ansiGreen)
+		} else {
+			app.markRow(key, ansiYellow)
+		}
+	}
+	return former
+}
+
+// dropWatchGhost removes the ghost of an item deleted by a watch event, it tells if there was one
+func (app *monitorApp) dropWatchGhost(key string) bool {
+	for i, item := range app.items {
+		if ghost, ok := item.(*removedItem); ok && ghost.since == watchDeletion && primaryValue(item) == key {
+			app.items = append(app.items[:i], app.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// insertItem adds an item at its place in the sorted list
+func (app *monitorApp) insertItem(item MonitoredItem) {
+	index := sort.Search(len(app.items), func(i int) bool { return app.lessItems(item, app.items[i]) })
+	app.items = append(app.items, nil)
+	copy(app.items[index+1:], app.items[index:])
+	app.items[index] = item
+	app.addPossibleKeys([]MonitoredItem{item})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2022-2023 Jean-Francois Smigielski
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyEvent(t *testing.T) {
+	app := newTestApp(testItem("a"), testItem("b"), testItem("c"))
+
+	app.applyEvent(ItemEvent{Type: EventAdded, Item: testItem("bb")})
+	app.applyEvent(ItemEvent{Type: EventModified, Item: testItem("c", "size", "2")})
+	app.applyEvent(ItemEvent{Type: EventDeleted, Key: "a"})
+	if got := listedIDs(app.items); got != "-a b bb c" {
+		t.Fatalf("items %q", got)
+	}
+	for key, style := range map[string]string{"a": ansiRed, "bb": ansiGreen, "c": ansiYellow} {
+		if app.marks[key].style != style {
+			t.Errorf("%s: style %q instead of %q", key, app.marks[key].style, style)
+		}
+	}
+
+	// An item added again replaces its ghost, and is marked as added
+	app.applyEvent(ItemEvent{Type: EventAdded, Item: testItem("a")})
+	if got := listedIDs(app.items); got != "a b bb c" || app.marks["a"].style != ansiGreen {
+		t.Fatalf("items %q, style of a %q", got, app.marks["a"].style)
+	}
+
+	// The ghost of a deleted item disappears with its highlight
+	app.applyEvent(ItemEvent{Type: EventDeleted, Key: "b"})
+	app.marks["b"] = rowMark{style: ansiRed, until: time.Now().Add(-time.Second)}
+	if !app.dropWatchGhost("b") || app.dropWatchGhost("b") {
+		t.Fatal("the ghost should be dropped once")
+	}
+	if got := listedIDs(app.items); got != "a bb c" {
+		t.Fatalf("items %q", got)
+	}
+}